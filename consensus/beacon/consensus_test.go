@@ -0,0 +1,138 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package beacon
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/consensus/misc"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// testChain is a header reader over a fixed set of headers, the total
+// difficulty is only known for the headers it was set for.
+type testChain struct {
+	config  *params.ChainConfig
+	headers map[common.Hash]*types.Header
+	tds     map[common.Hash]*big.Int
+}
+
+func newTestChain(headers ...*types.Header) *testChain {
+	config := *params.TestChainConfig
+	config.TerminalTotalDifficulty = big.NewInt(10000)
+	chain := &testChain{
+		config:  &config,
+		headers: make(map[common.Hash]*types.Header),
+		tds:     make(map[common.Hash]*big.Int),
+	}
+	for _, header := range headers {
+		chain.headers[header.Hash()] = header
+	}
+	return chain
+}
+
+func (c *testChain) Config() *params.ChainConfig  { return c.config }
+func (c *testChain) CurrentHeader() *types.Header { return nil }
+
+func (c *testChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if header := c.headers[hash]; header != nil && header.Number.Uint64() == number {
+		return header
+	}
+	return nil
+}
+
+func (c *testChain) GetHeaderByNumber(number uint64) *types.Header  { return nil }
+func (c *testChain) GetHeaderByHash(hash common.Hash) *types.Header { return c.headers[hash] }
+func (c *testChain) GetTd(hash common.Hash, number uint64) *big.Int { return c.tds[hash] }
+
+func powHeader() *types.Header {
+	return &types.Header{
+		Number:     big.NewInt(100),
+		Difficulty: big.NewInt(131072),
+		GasLimit:   8000000,
+		GasUsed:    4000000,
+		Time:       1000,
+		BaseFee:    big.NewInt(params.InitialBaseFee),
+		UncleHash:  types.EmptyUncleHash,
+	}
+}
+
+func posChild(config *params.ChainConfig, parent *types.Header) *types.Header {
+	return &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		Difficulty: new(big.Int),
+		GasLimit:   parent.GasLimit,
+		Time:       parent.Time + 12,
+		BaseFee:    misc.CalcBaseFee(config, parent),
+		UncleHash:  types.EmptyUncleHash,
+	}
+}
+
+// TestCalcDifficultyUnknownTd checks that the difficulty of a child of a
+// proof-of-work block is only derived from a known total difficulty.
+func TestCalcDifficultyUnknownTd(t *testing.T) {
+	pow := powHeader()
+	chain := newTestChain(pow)
+	pos := posChild(chain.config, pow)
+	chain.headers[pos.Hash()] = pos
+	engine := New(ethash.NewFaker())
+
+	// a child of a proof-of-stake block is a proof-of-stake block
+	if diff, err := engine.CalcDifficulty(chain, pos.Time+12, pos); err != nil || diff.Sign() != 0 {
+		t.Errorf("child of proof-of-stake block: have %v, %v, want 0", diff, err)
+	}
+	// without the total difficulty the child may be either
+	if diff, err := engine.CalcDifficulty(chain, pow.Time+12, pow); !errors.Is(err, errUnknownTd) {
+		t.Errorf("unknown total difficulty: have %v, %v, want %v", diff, err, errUnknownTd)
+	}
+	chain.tds[pow.Hash()] = big.NewInt(9999)
+	want := ethash.CalcDifficulty(chain.config, pow.Time+12, pow)
+	if diff, err := engine.CalcDifficulty(chain, pow.Time+12, pow); err != nil || diff.Cmp(want) != 0 {
+		t.Errorf("before the terminal total difficulty: have %v, %v, want %v", diff, err, want)
+	}
+	chain.tds[pow.Hash()] = big.NewInt(10000)
+	if diff, err := engine.CalcDifficulty(chain, pow.Time+12, pow); err != nil || diff.Sign() != 0 {
+		t.Errorf("terminal total difficulty reached: have %v, %v, want 0", diff, err)
+	}
+}
+
+// TestVerifyHeaderFirstPoS checks that the first proof-of-stake block is only
+// accepted once the total difficulty of its parent shows the merge happened.
+func TestVerifyHeaderFirstPoS(t *testing.T) {
+	pow := powHeader()
+	chain := newTestChain(pow)
+	header := posChild(chain.config, pow)
+	engine := New(ethash.NewFaker())
+
+	if err := engine.VerifyHeader(chain, header, true); !errors.Is(err, errUnknownTd) {
+		t.Errorf("unknown total difficulty: have %v, want %v", err, errUnknownTd)
+	}
+	chain.tds[pow.Hash()] = big.NewInt(10000)
+	if err := engine.VerifyHeader(chain, header, true); err != nil {
+		t.Errorf("terminal total difficulty reached: have %v, want nil", err)
+	}
+	chain.tds[pow.Hash()] = big.NewInt(9999)
+	if err := engine.VerifyHeader(chain, header, false); err == nil {
+		t.Errorf("terminal total difficulty not reached: have nil, want error")
+	}
+}
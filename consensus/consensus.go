@@ -111,8 +111,8 @@ type Engine interface {
 	SealHash(header *types.Header) common.Hash
 
 	// CalcDifficulty is the difficulty adjustment algorithm. It returns the difficulty
-	// that a new block should have.
-	CalcDifficulty(chain ChainHeaderReader, time uint64, parent *types.Header) *big.Int
+	// that a new block should have, or an error if the engine can't tell.
+	CalcDifficulty(chain ChainHeaderReader, time uint64, parent *types.Header) (*big.Int, error)
 
 	// APIs returns the RPC APIs this consensus engine provides.
 	APIs(chain ChainHeaderReader) []rpc.API
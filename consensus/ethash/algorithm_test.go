@@ -0,0 +1,124 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethash
+
+import (
+	"bytes"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Tests that hashimoto running on a tiny cache produces the correct results.
+func TestHashimoto(t *testing.T) {
+	cache := generateCache(1024, make([]byte, 32))
+
+	hash := hexutil.MustDecode("0xc9149cc0386e689d789a1c2f3d5d169a61a6218ed30e74414dc736e442ef3d1f")
+	nonce := uint64(0)
+
+	wantDigest := hexutil.MustDecode("0xe4073cffaef931d37117cefd9afd27ea0f1cad6a981dd2605c4a1ac97c519800")
+	wantResult := hexutil.MustDecode("0xd3539235ee2e6f8db665c0a72169f55b7f6c605712330b778ec3944f0eb5a557")
+
+	digest, result := hashimotoLight(32*1024, cache, hash, nonce)
+	if !bytes.Equal(digest, wantDigest) {
+		t.Errorf("light hashimoto digest mismatch: have %x, want %x", digest, wantDigest)
+	}
+	if !bytes.Equal(result, wantResult) {
+		t.Errorf("light hashimoto result mismatch: have %x, want %x", result, wantResult)
+	}
+}
+
+// mainnetBlock1 is the header of the first block of the main network.
+func mainnetBlock1() *types.Header {
+	return &types.Header{
+		ParentHash:  common.HexToHash("0xd4e56740f876aef8c010b86a40d5f56745a118d0906a34e69aec8c0db1cb8fa3"),
+		UncleHash:   types.EmptyUncleHash,
+		Coinbase:    common.HexToAddress("0x05a56e2d52c817161883f50c441c3228cfe54d9f"),
+		Root:        common.HexToHash("0xd67e4d450343046425ae4271474353857ab860dbc0a1dde64b41b5cd3a532bf3"),
+		TxHash:      types.EmptyRootHash,
+		ReceiptHash: types.EmptyRootHash,
+		Difficulty:  big.NewInt(17171480576),
+		Number:      big.NewInt(1),
+		GasLimit:    5000,
+		Time:        1438269988,
+		Extra:       hexutil.MustDecode("0x476574682f76312e302e302f6c696e75782f676f312e342e32"),
+		MixDigest:   common.HexToHash("0x969b900de27b6ac6a67742365dd65f55a0526c41fd18e1b16f1a1215c2e66f59"),
+		Nonce:       types.EncodeNonce(0x539bd4979fef1ec4),
+	}
+}
+
+// Tests that the seal of a real block verifies against the full size cache of
+// its epoch, and that a different nonce doesn't.
+func TestVerifySealMainnet(t *testing.T) {
+	header := mainnetBlock1()
+	if hash, want := header.Hash(), common.HexToHash("0x88e96d4537bea4d9c05d12549907b32561d3bf31f45aae734cdc119f13406cb6"); hash != want {
+		t.Fatalf("header hash mismatch: have %x, want %x", hash, want)
+	}
+	ethash := new(Ethash)
+	if err := ethash.verifySeal(header); err != nil {
+		t.Fatalf("valid seal rejected: %v", err)
+	}
+	header.Nonce = types.EncodeNonce(header.Nonce.Uint64() + 1)
+	if err := ethash.verifySeal(header); err != errInvalidMixDigest {
+		t.Fatalf("bad nonce: have %v, want %v", err, errInvalidMixDigest)
+	}
+}
+
+// Tests that a loaded cache is used instead of a generated one, and that only
+// the cache of its epoch loads.
+func TestLoadCache(t *testing.T) {
+	header := mainnetBlock1()
+	data := CacheBytes(header.Number.Uint64())
+
+	ethash := new(Ethash)
+	if err := ethash.LoadCache(header.Number.Uint64(), data[:len(data)-hashBytes]); err == nil {
+		t.Fatal("cache of the wrong size loaded")
+	}
+	if err := ethash.LoadCache(header.Number.Uint64(), data); err != nil {
+		t.Fatalf("failed to load cache: %v", err)
+	}
+	if err := ethash.verifySeal(header); err != nil {
+		t.Fatalf("valid seal rejected with the loaded cache: %v", err)
+	}
+	// a rejected cache leaves the generated one in use
+	data[0] ^= 0xff
+	ethash = new(Ethash)
+	if err := ethash.LoadCache(header.Number.Uint64(), data); err == nil {
+		t.Fatal("corrupted cache loaded")
+	}
+	if err := ethash.verifySeal(header); err != nil {
+		t.Fatalf("valid seal rejected after a corrupted cache: %v", err)
+	}
+	block := uint64(len(cacheHashes)) * epochLength
+	if err := ethash.LoadCache(block, make([]byte, cacheSize(block))); err == nil || !strings.Contains(err.Error(), "no ethash cache hash") {
+		t.Fatalf("cache of an epoch without a pinned hash: have %v", err)
+	}
+}
+
+// Tests that the pinned cache hashes are the ones of the generated caches.
+func TestCacheHashes(t *testing.T) {
+	for _, epoch := range []uint64{0, 1} {
+		if hash := crypto.Keccak256Hash(CacheBytes(epoch * epochLength)); hash != cacheHashes[epoch] {
+			t.Errorf("epoch %d: cache hash %x, pinned %x", epoch, hash, cacheHashes[epoch])
+		}
+	}
+}
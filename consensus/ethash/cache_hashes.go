@@ -0,0 +1,545 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethash
+
+import "github.com/ethereum/go-ethereum/common"
+
+// cacheHashes are the keccak256 hashes of the verification caches of the epochs
+// up to the merge of the main network, in the encoding CacheBytes returns. A
+// cache loaded from the oracle must match the hash of its epoch.
+//
+// Generated with crypto.Keccak256Hash(CacheBytes(epoch * epochLength)).
+var cacheHashes = [...]common.Hash{
+	common.HexToHash("0x35ded12eecf2ce2e8da2e15c06d463aae9b84cb2530a00b932e4bbc484cde353"), // 0
+	common.HexToHash("0x56c3d5f76af4c322ee18b3b40f76a7b5715838a6220e4ec9435cc5bd39ec0546"), // 1
+	common.HexToHash("0x4c2ac08b31854b2ca3b4e9b2d860cdcf0d1c88cd9dc4263fda1a6fcc8dcb11ae"), // 2
+	common.HexToHash("0x7eca22d70128c485e6567a98d53d0e3d74c6ae57809fa19ce661523788e4b8c6"), // 3
+	common.HexToHash("0x14637621527240d1dfabd9fa583f366e272b404735a46a19f1c5538b0ad7c03d"), // 4
+	common.HexToHash("0xa1d6409449be524ca72ff64a16bee365e381e948cf60466a59135cbb2428cc27"), // 5
+	common.HexToHash("0xe27d205b259df3ebe4865150ef9ec90e1b64125e7ad88507ec030ba11cf2596e"), // 6
+	common.HexToHash("0x70b497e04d56d88a7c053cdd0d496d5a8811430c3c06281ab96de537c7188718"), // 7
+	common.HexToHash("0xd09f63b3a9df65ffe51179cec845715ed38329966b52bb1d1853761b448d81ac"), // 8
+	common.HexToHash("0x9e2f80437835c52684865b4977ae75a70f9f304641952fd62fb668f14527bcc0"), // 9
+	common.HexToHash("0x3b567af2621087f543016d124c38c4c9c405b0dbffaae576198df9e44e3ce30c"), // 10
+	common.HexToHash("0x6886a88e14645816124cfb38e288420b848a843f92e846556f1cb030e6edbbd4"), // 11
+	common.HexToHash("0xfb7b65e77811c231c7c50672c279d1ee9cab524c49a7cce80814aedae653ea93"), // 12
+	common.HexToHash("0xcd7bc9c6b41bc14342790f29f0b5bbf751a0afcdc9695f2e4fbe5e9e6f2cb940"), // 13
+	common.HexToHash("0xfdd4906dab340dea502ce1f180cb974195eab3548f7f87ff16c85ecba2581417"), // 14
+	common.HexToHash("0xe6e9302c8ff6d348a2260c8cb07fefbe6f8ed38703cd03e364cde7d9546dce1b"), // 15
+	common.HexToHash("0x9ca9800853719603bf7acdeecef1fc071b7140f52b24bd8c46294960fb17e537"), // 16
+	common.HexToHash("0x48459593b5b5227bd117fec3a5cc242359d3ed3e88a7d24031ef406ed81a296f"), // 17
+	common.HexToHash("0x422896b34f36e5f5f07fb88b907a7f7a0889ed2928b8b4b2d9f39cff7d31c66a"), // 18
+	common.HexToHash("0x7ba6f32498623ef41ef4ab30d2b97c48948be7c8e483411b8f303eb10a118a9a"), // 19
+	common.HexToHash("0xb4680fac0cfd8570feea0b58a5dba37eea4f6dd761b8e682b8aab83f861eb21f"), // 20
+	common.HexToHash("0xee889eaef4983dd4c95a3671a46f781fd2fa1a8c1d482fb235d8a24926d1c556"), // 21
+	common.HexToHash("0xaad940fc8630d063b981e86ced493e45b47abbf72340c9cd8a9a41b369a045b5"), // 22
+	common.HexToHash("0xeef14e0200a7372437f887c2cc4f239b5ae87faf1a87dab326fac0fda0596417"), // 23
+	common.HexToHash("0x8e2592e7ed494b921525a6435fdfb4e2643490e776c05e95d7af48d4313ff542"), // 24
+	common.HexToHash("0x08d905fa7e67dd4b0d471333d86c996ad5bee2b223101cf60d5adfab38a05e12"), // 25
+	common.HexToHash("0x026d9228ba0d9a8380bfc878da1e6ce690ec1b660e4c312689de40e03e999fe3"), // 26
+	common.HexToHash("0xf48ad402305e721e5fafebb2f9c779898d96260e4f384b4fd710e39f7cd080bc"), // 27
+	common.HexToHash("0x1b2d62a3b29c219411dcc74766fb327a5e5c2b1cbb54a6a8966ad6d1fd44d1c3"), // 28
+	common.HexToHash("0x593bfb5450ffcba09797b336079d762f746c6e09b25366f6a7851dd090f99609"), // 29
+	common.HexToHash("0xe6fe38ae54afb48b00c9c0e468dd60c1818af505b7c146df7adefcd6a22ebcdf"), // 30
+	common.HexToHash("0x52284006e49d50e5d63d7f8fb2f39b2d36fc8e43985ea2c6bc48bb13fedd7831"), // 31
+	common.HexToHash("0x33d2ba39d93fc04f5f00b40b5ccd45a8fc7a6c9f47d5c0898584dfbc1018cba5"), // 32
+	common.HexToHash("0x46bd544328828368e761439c4a7592e695c8d628a7b050d21f47f5000057bbc5"), // 33
+	common.HexToHash("0x8d003c684e7ea4cb7a51e80bfaedc80722cc5ca923733ec72345d03036191c34"), // 34
+	common.HexToHash("0x9f0b135bbe73aa88ea001f3e58a2f10b957b1ad3dccfeaba6b15ee46ada86744"), // 35
+	common.HexToHash("0x2d313d40a4ea89b06f79b46960759da1b586910c3bc9e3c992aea4b99836a758"), // 36
+	common.HexToHash("0xf417d191d597297f3af7e84986a49c7522773fddc88802ef27e2fcbddee7b116"), // 37
+	common.HexToHash("0x51c4acb72ed46ee493dec51a63fff1540b9d72390434e3c154cb18f8cf682d90"), // 38
+	common.HexToHash("0x0f92bb5465a65371cade4a88b983890cbc0a1e047de07027254c092692358a63"), // 39
+	common.HexToHash("0x03c40ac8e123382e9078db88bff11dda45eb65839f15634f09371f1368eea328"), // 40
+	common.HexToHash("0x5d01a5b2bfb5e2504b3b4c45c39cfe39b21174277a42833f6020b58dd1031750"), // 41
+	common.HexToHash("0x5ff5ff6d016df84ed7f7d1612e032902f30e90dfb37c8816a3e73c4a81001045"), // 42
+	common.HexToHash("0xf3de7050c0a5f0b0b16931f14fc31f9c31c94688765c08e313430e9374aea32f"), // 43
+	common.HexToHash("0xd0ed10d88c082e501631a85c01e423139fbcee1c44e7a64ce17abcf61e5ee1b6"), // 44
+	common.HexToHash("0x0bdf37e561aacce3c533296622eea09311984314fdd9527b2cf0db710b079d0c"), // 45
+	common.HexToHash("0x9e0bc708b4d40ec6de669f333e4c75bfc1f32a4b91cf81251f2ff0d63fe25634"), // 46
+	common.HexToHash("0x2a79e3b89a45c14d46a3f8b9017e55b934c657950a3acc08c2cea175893d76e6"), // 47
+	common.HexToHash("0xaf293b260a01888c2dc39f2cb0c8ab25c40d104a77b8174dbea0eda87822f0d5"), // 48
+	common.HexToHash("0xa043ae40be4cdac237fa9e96d24b2e48f8ea4fe0492faa6cd4cbd41bf8de96d0"), // 49
+	common.HexToHash("0x677d4a5c809cbc3fa23676e3df38bd2b9411157941f4aaf4d272cb28a583ed83"), // 50
+	common.HexToHash("0xf2424d257753082a8bb4e794a2682749f205d28da278a05091caf6fd86f75063"), // 51
+	common.HexToHash("0xd723e5ff5f9e4f0cdf8eda11ca22297a00502817aef1622704e7295a71ffb7af"), // 52
+	common.HexToHash("0xfa0f4d265a95a3f2b50d04a9cf2b4d80cecb0c55b06d509154dd3b68dd4c95bf"), // 53
+	common.HexToHash("0xa9b416e0ef7fabced4178f6e7b7fdda1dfff793e01982ead80958c12117701b5"), // 54
+	common.HexToHash("0x518f7fbbd6e8cdf967907426b9b0d1fd9df0c059b9ae5759fa6e06afe2e0c2dd"), // 55
+	common.HexToHash("0x0af901fca63be518e7b66cbcb584dd76d6b8749061c1ad33eb9e6201d084d8b6"), // 56
+	common.HexToHash("0xaa09c8e4f1c6cab8d05f57fa9e31f827124c1e89b9d810c83d6721884a66c775"), // 57
+	common.HexToHash("0x7a8e8fda0442d61982e1dfa387cbd6514f43e9c34266ddf03eff29f119450252"), // 58
+	common.HexToHash("0x6d61fa43ad33353c3764eabcf4a8833b82f4c381da1e457385bbf5639691d42f"), // 59
+	common.HexToHash("0x808e9ed3401e08da679225ca915e2ceda8c06e750e97e1571b448aba3484de6f"), // 60
+	common.HexToHash("0xc49fd08416b76db9cbe43063ef4d458391ead0767fd559a77da81ce034818efc"), // 61
+	common.HexToHash("0x5cd0341e1f6061e99a6957b5ee73058302fb1c6c01baf42e5ff10a50f411d024"), // 62
+	common.HexToHash("0x186b49a5f401beda1358fe2051fbe75702e8a90f725090423bbe8e1e6124e466"), // 63
+	common.HexToHash("0x662b6c8f04cf0ae97b452aa11f33a1af9aa6a1d634ae9bbd612bd13f7469ee67"), // 64
+	common.HexToHash("0x155aefa0f999894902ed5cc87fd4f8824798ce9ac580e3b7531fa8b6362397ff"), // 65
+	common.HexToHash("0x348306ef2679f8e8adac62ff2fb62d223291438d53567f713dd435a7fc4e7e4d"), // 66
+	common.HexToHash("0x2cbd1f8f5176f4aade9566755d2e3eac3abe5115a9364ecfe9813c8caa340325"), // 67
+	common.HexToHash("0x430e1c6c6366c6bc002281b2edf986477cdb960de256d01e340bb32b89673544"), // 68
+	common.HexToHash("0xb09d68a2a529da2a4c4b16a305828ca8177e1e18c05b17a83a4f0b02fca06fd4"), // 69
+	common.HexToHash("0x8908b49062edb73a5fbe7243ec187bcdfc5a6ecfcc9c961a1e10b8a920262f83"), // 70
+	common.HexToHash("0x2e785a585440ffc0166b8e254a362e23797c1bd135a80eca11aba0840e6f2e12"), // 71
+	common.HexToHash("0x1ab590af93d485504a4cafb46197bf42a7495b9ce1bedd7ee6719e58e2e5b131"), // 72
+	common.HexToHash("0xb7ac78532d8d0b9f13eaadf84f6ebc9a2362297c946ff99596389dcd0440fbbb"), // 73
+	common.HexToHash("0xe3263fb1a8f52aeab33e8c99815f6d2aea4997212ac27bb15926c3c91ad399d1"), // 74
+	common.HexToHash("0xedb83b9d509a49b58d8faeee683df02a4973fd3561bc939a4dc08655ddf8879b"), // 75
+	common.HexToHash("0xc12dcc79cb43efe98fa1c0220b01348b9d21b881942bd0057e9e38ebf70642f1"), // 76
+	common.HexToHash("0x90571817aa5f0661de2f3e82e67b151bb9e1fa41bfb0dd91bf32041a2e7d2cd7"), // 77
+	common.HexToHash("0x557492a818ca788b4efbce8b80ff33fb13b24a3910a268d2ea059456ebf70b86"), // 78
+	common.HexToHash("0x3724d1405f803c0df33eed7533d3a7f711825baf88747a47c6b5a7872e25e868"), // 79
+	common.HexToHash("0x37b8806f3d45a08edfef9c89e2ed322c8a3b6e604a96b5b697e5f63524821aba"), // 80
+	common.HexToHash("0xddef5129501d5780907f81234c677dc587530b49a4e6f0624627d0772e3937c8"), // 81
+	common.HexToHash("0x5658294945fef7bf40879d70e5763b0382cd8aa1cff8beeff87e65735871becc"), // 82
+	common.HexToHash("0xcc6dfc86929e32ae61cd2ca322cf9708d6debce6679730b18b3c17f3eea79c54"), // 83
+	common.HexToHash("0xafa2a00911843b0a67314614e629d9e550ef74da4dca2215c475a0f93333aedc"), // 84
+	common.HexToHash("0x6bc9be4ee300330c493ba2a83208907c1a96d6e6c390cd8f62d1cab9fbd96487"), // 85
+	common.HexToHash("0x243327d04c754cee54d4267d77629edc11f1cb752112d19c4348fb6853f4a1e3"), // 86
+	common.HexToHash("0xacfb5a933377571dcf1dff23a04326e25654c9b1183edf7df9e268149703ae2e"), // 87
+	common.HexToHash("0xf846e74b6e57a9a49f945240408d68b7e997e6aeade5792e575046abcc35c45a"), // 88
+	common.HexToHash("0xbc5c2e7b678d7fb24159dec9e2cda07a839c411878632474cf9b29eb3600c77c"), // 89
+	common.HexToHash("0x2526237b4887f0980e5984eaf28493be5727bb29ad161a99d18ac653ce772141"), // 90
+	common.HexToHash("0xdd47f2d5369365ed2e801196be6b160ad53f7149926b0c37547bc2a5b2b75b88"), // 91
+	common.HexToHash("0x3ff74a4046889fa81771113f05ccef95b2112f7c21e0bfdb74401be110c77839"), // 92
+	common.HexToHash("0x7c851a470ae9fa1ed67193e1bd6eaf9ea6fb831e76791d8feb0096706b7090ba"), // 93
+	common.HexToHash("0x92603f14daa46e9ff0cb5e692cddf984748d23d0371f03540e6a16c1f4d006ed"), // 94
+	common.HexToHash("0x10307ceb04bcb3a5d82fa5ad7e4bedb1e38137e7e951f5ec228c93293f3b6d8f"), // 95
+	common.HexToHash("0x073f4bdfd9b485f9bb61a349a629d483586a599a01305592a3059ce4edbbc591"), // 96
+	common.HexToHash("0xdf17b46a341965b817a7c63a3e60555e2fd936a7f51e61106c1f25ba6b59ed4b"), // 97
+	common.HexToHash("0x3dd500c68e8edd0c2263124c1f2c90617afaab5d7d658050f231a478fbf0be8f"), // 98
+	common.HexToHash("0xc4da6a11ea415b050dad5b99ea289dd51e44541ac09306cf71a7f6057538b5eb"), // 99
+	common.HexToHash("0xd015325a0085f096f40474399c30ffc083d624a99ad269c62566ab14a43e4724"), // 100
+	common.HexToHash("0x0817b018c3d7ffb321895509f79ceeb9f88323f074a2932fff11acecfe81e643"), // 101
+	common.HexToHash("0x0dac298e5e3fe6a25fe0c8740bae432ba47d2327ea27e38f9a0c8fd3723b0675"), // 102
+	common.HexToHash("0xd31f607a67df069495675c51dc83243843989b674e20ab9351b81d6f638b7d7c"), // 103
+	common.HexToHash("0x636c07b053216c66994eaa25232dfd580aa6092c41eeca9bf2b10e1ee88d2253"), // 104
+	common.HexToHash("0x05809e64f187b23b66d7e22e886a28759ae851f465ad402fcb432ea0729d7ed2"), // 105
+	common.HexToHash("0xa452302c3deefe95d6ec696e1c8f506a86863c59945a64fe56de386dd3792fc5"), // 106
+	common.HexToHash("0xbcfb6fa541b9bc8f21faa06d90bf0f3904030b3990df4baee03bcfdea1ccb0c5"), // 107
+	common.HexToHash("0x003e273cbf057b4c9d6d07cdd30ee41c1d2fa8983b231c9dcc45c2e4871a4f2b"), // 108
+	common.HexToHash("0xb290ef6a3b715771630870a9109e6188bfc2f4c7f05ed524ec2b63d9cc538298"), // 109
+	common.HexToHash("0xd322ef4d21ea6b0e585e5a5fc84f7b5220612828c940ec60d6cc0abfcdf55e3a"), // 110
+	common.HexToHash("0x841c55fab2564aba4a6b7c17b80bf8cc0adbfd9754f05fbeaf68a8651ea4f236"), // 111
+	common.HexToHash("0xf60cc44d48c521c115c8227782e3e8ee803e880cede8c20c95e35f8331265da2"), // 112
+	common.HexToHash("0x80a9b68508678e4be2293a6fcd872e0182518b7c1ed16f37979a3fe44235bab2"), // 113
+	common.HexToHash("0xc0a2686437cada7917a0fc8115ec6ea6f29b849e760d23924ddee99983a71f72"), // 114
+	common.HexToHash("0x15f0c92660176c73ec156170264e6402b4685179148254fb90f412500cb9140e"), // 115
+	common.HexToHash("0x6006b7e5ecef6cd89b63c7dd4c8ebcbd9dcbc368c9fa4f5cb237d987e98f69d0"), // 116
+	common.HexToHash("0xebacbca79b5480e51b00ed736f7cb18b9cddff25a9ada32abb0aa99b19f2b1bc"), // 117
+	common.HexToHash("0x7de8a096fd0fcc92cd3fc85ac6900c15b2a37ba1c7825a206f23540bdf847dc3"), // 118
+	common.HexToHash("0x25fb629b61b046d02b76741ea9ccda3529cf37ba8ee5a8a4caae02d625d3335d"), // 119
+	common.HexToHash("0x1ea23a0bcddb906a112d1f9039d38a03ed7757751f281445e123a59d4ed6baf3"), // 120
+	common.HexToHash("0x68fa65073ba5f2274bef4620d613d927bd665313e7b87a678188aa7132a812c6"), // 121
+	common.HexToHash("0x7ba9740709525343205386355eae9cc27406a1e96961639cf62668065e4b9654"), // 122
+	common.HexToHash("0x94a4f0d0c2b6a71c5816418fdd12be432162a84511f71ae3ebf1f40da193b6da"), // 123
+	common.HexToHash("0xd37ec5af766e54446a61c59123aa291225e21e9274bbb7f56b98710bff39fdd3"), // 124
+	common.HexToHash("0x003f16ea7e596297ebfb2dbae83ed5e96137346c3c8aeb83d1d3dc5735e92637"), // 125
+	common.HexToHash("0x9e659e5b642d7b6c54e09c6e74d0711da8f17b10c79d0b59311712038b9aac05"), // 126
+	common.HexToHash("0xf8d90fd9aa0a70935083e5bcd0f00af8776e7a9c7077c357cd2d3e3c74117ef8"), // 127
+	common.HexToHash("0x99718e721dccfb14c93832c01fd6df87b702109b94333e433b639c1f1886ad3d"), // 128
+	common.HexToHash("0x44459290250c179457216524016dea0f73ab3bb4c6ae0bfc1232e7f577ebfe98"), // 129
+	common.HexToHash("0xff9248c36055ab5db244594c0454397e41ff906e50ad04009eb72ec06a6838ec"), // 130
+	common.HexToHash("0x0ab9ba9b05209c02b662099cf6ca8f05f033224a8e76f66875bf77ad6d8ac46e"), // 131
+	common.HexToHash("0xb628475892b8bd92ec31a9effe25a7b549b44a90c55bf83e4efcebd3d2ce09fb"), // 132
+	common.HexToHash("0x3989652607191a0c0d9fe051addea4d5509b755b8275ebc5e0cb733c6225f745"), // 133
+	common.HexToHash("0x0dbcc40ed9ce24e5889ea69b2f1579ece9bc92621a035abc5e3b81fa183bf085"), // 134
+	common.HexToHash("0x018677aad8a766f4bf7973e5159f93fc0a2be6ed488e1d32041d012eb8223153"), // 135
+	common.HexToHash("0x5132823fcd44fd4658da7a6389015dcbd5699d9160f8d519fcbe64e322855340"), // 136
+	common.HexToHash("0x30a940756181c417130efdffc0508154c50887eb33242e307d8e29bc6746201c"), // 137
+	common.HexToHash("0xf0429838ea354823d0ac5fd65ffb172e50f8594b4fbd394f34cd17ec70caafdc"), // 138
+	common.HexToHash("0x27067142b8b5e72940f783f44ad2c6d6ea1880c183e0da5771c82608338f9912"), // 139
+	common.HexToHash("0x60ff6824ccaf89ed69c60b3548d757bb82066154493ea307dfe3fb8812fcfb4a"), // 140
+	common.HexToHash("0xa81154e56be5982301e042986a36b50b1d495b36c7c9d18c2391fd0e2bbde8f5"), // 141
+	common.HexToHash("0xe82881cd60a1260676e614b14ad057920a88794129ea5d004cb0f171ee8b4734"), // 142
+	common.HexToHash("0xbf182abc7c1125682324177607aceab2175e134f5a02585a8d9e3ba2ad01a2d7"), // 143
+	common.HexToHash("0x57e24fa649804a85bff22afaa7ad27c24aee435455dc52316a96c74e6c7602b8"), // 144
+	common.HexToHash("0xef7bcd1edab4b08eda44d8c6bf2803a6c13bffd6365d3ec11354527e41a76230"), // 145
+	common.HexToHash("0x67e8891ae07dfcf70fd2d4fb2446a9675226e764bbeeefe43e5a3cc0fe3882fb"), // 146
+	common.HexToHash("0xf1fd97d0c31eec542c8de18bd4aea7ded63dc09320c4fb042efdf58dd7382aa4"), // 147
+	common.HexToHash("0x0bd80d80b4ed0f89ede04c724c2a859caaa19c7d5cfed4222646540207342f25"), // 148
+	common.HexToHash("0xd725f6ef9536aa8b7ae8d1742afa98322d52c28e23e6c2e888995e977c7b6b74"), // 149
+	common.HexToHash("0xfe81052eacb68346083b8e817cd1f63fad00a836e2539212be1e8166191451f1"), // 150
+	common.HexToHash("0x307c9573a2861380d5f9079c79e5bfaa695ca192ab7c956972f482f6c26c6723"), // 151
+	common.HexToHash("0x054b89fa209028c6b76665ad2dca462c4434a66faee1914057e43ce7e6641c41"), // 152
+	common.HexToHash("0xc948505ad354ecf448f4d8814c8e358d47da32c080f91530aed692a9d764657e"), // 153
+	common.HexToHash("0xe2b07ead8b96f9288bbfbe38a1cd139b2c1b2394112e703c038fd363ce6077e6"), // 154
+	common.HexToHash("0x2a32c5c2eac8c6bf1cd497f258f09ce3a08240a25bd24c457755f66a66f5519e"), // 155
+	common.HexToHash("0x23936cc626f6c271197389f073e3dfb825191fbcd0a3adf4b3283deaa8edba34"), // 156
+	common.HexToHash("0x3f467e38c92d8fc98b795e64364cc9b0fe297ea8496e9b7c8a32cce6314bdd14"), // 157
+	common.HexToHash("0x079d4f20ae659d4561a858b6da9e1654dc51ba6693b33ca26d2bb5a28bdbb17a"), // 158
+	common.HexToHash("0x93c8e4d173d7c48637c64ec54dd08ca7d27f5a3b76644ff658fa1230386376fb"), // 159
+	common.HexToHash("0x7a9909dc9ea8d1a8f6eff3d7127a240c5a7546bbbc6a26fad7f3c4d78368a74a"), // 160
+	common.HexToHash("0xe41d0ed53b252196d3ae9f9ddb044fd4f8aa2424c1eb9b98a48d3ef2cbdcfd66"), // 161
+	common.HexToHash("0xedd7248cacff6d8b95f4c537138b995e8441a601faf2891f1f356fe10d8b91bb"), // 162
+	common.HexToHash("0xe4412fae0703c961e3603389e666ea914cd828b7d72951940b4118221fb7f7a1"), // 163
+	common.HexToHash("0xb771e7683bd21a8b99d59e4398a0773ec5caf034f2fa06b9521a43e173d214f6"), // 164
+	common.HexToHash("0xa57e58221d7b9d8d6ad4e9de31a5442c313c69c824b1871e1ad2c7146a0d63af"), // 165
+	common.HexToHash("0xe47cc77f6bc4ef9f73311eee35385081e7611ee2f3200f259d4d2e501e9a7df6"), // 166
+	common.HexToHash("0x337ac8e69a9e1609adeb78b7cab3ecd3496001919da5ce93dcc0d65161fce938"), // 167
+	common.HexToHash("0x15255126ffd69999dc8f9d95846036a7b5743c992aa3349e4a7f897dc1462cac"), // 168
+	common.HexToHash("0xd3766d7a08471142f733717576fcc59ce265cb55f6fd557992e7d3a2d2033406"), // 169
+	common.HexToHash("0x27b001182730f5e19668e964a359c7e1237b68d5dc7ef2ac2abe35b36ee25051"), // 170
+	common.HexToHash("0x468ef97519bd780a0dbd19d46c099118d6f4b777c1b8d0d4b0d6f62a5018100e"), // 171
+	common.HexToHash("0x45b02be6af46c011789b928ebabbba3bda2822ad2faa973a6d54fc2141596c6f"), // 172
+	common.HexToHash("0xccbafb8ce2c0fb4dc2245da2457a04779fdceee7eac5864cc13020931d15ef87"), // 173
+	common.HexToHash("0xe50c86c2affc90225e9fc4008d15c5ab8bb6c637344a0f717e1aab7de2903b7b"), // 174
+	common.HexToHash("0x164158aaae9f8814917baf25f03c12031d09696a47ccb2696d04914e372515b1"), // 175
+	common.HexToHash("0x66d852ddc33cc5702ed5279bd48fe32451a65c791f742d898c7faec14c9fff3b"), // 176
+	common.HexToHash("0xc30279964379d1c3470608870ae6586dab75529cc0efac5c431a022e207fbacf"), // 177
+	common.HexToHash("0x5ce7effaf5cac0f301adf3f1348015b26d3f600ed83ec4ce2bf223f1e495cf9c"), // 178
+	common.HexToHash("0x7b31deb59798ad11b74398ff5edbfac47dac598c509ebf776ea8866864f58606"), // 179
+	common.HexToHash("0x657f8f475eb592e05d730a51b3b5ad21e1e53dad9703e3e2ae8a3533b129ecc7"), // 180
+	common.HexToHash("0xd38a310fc046ce13652754b65b8fd25b398d7785992554379aef952afb4e26b7"), // 181
+	common.HexToHash("0x225afdb28535190e189d24b0dd62a00ad0a9dafe2ef09dfc7d56edbdc42e9610"), // 182
+	common.HexToHash("0x7e1393a0759c3af8ea07948db9b1726c27954e263aa632bcd681da343d2411fb"), // 183
+	common.HexToHash("0x76d018b03b1582ec5e76191cae2b19dc3bf0ef80f4c04307656b7d126abb9859"), // 184
+	common.HexToHash("0xb33781f8e9e28edbeb9608f88be35fa198679cd0f4814283c71e86511b7d46ec"), // 185
+	common.HexToHash("0x1073efc354d383e8dec2407c4bd847dbc49257a1249e6fde422737c0a42ae3b4"), // 186
+	common.HexToHash("0xfd711d7317d99e727b30ec3759bfad04d329ff3c788d44f8467f77992e62d1fc"), // 187
+	common.HexToHash("0xbe294717ebdbdf06faac144b0d9415394449fb10a6d5f34fbd8367b0d385ed08"), // 188
+	common.HexToHash("0x9834522b2acfcfcbcfc938ff144f4f4732ab6452d0a61f422efc1950ed2a8d4a"), // 189
+	common.HexToHash("0x278281f430ba765202648c13aecbfa101fc1bf9307bd1fc9f1d614f5b77ad46d"), // 190
+	common.HexToHash("0x4a7e1303fbbbcd39dc8bdfab6c72e446036fffa592b6e383e4156ec52bc2f5ee"), // 191
+	common.HexToHash("0xcd498a7e8857814b481553adec735a80aadb1d5db58d0112c0ab70829f61ef7d"), // 192
+	common.HexToHash("0x449e6a7943b62728bf22d78cc7216eef965e71047bb017bfe5e1e2345bfa5a8e"), // 193
+	common.HexToHash("0x26461ffd3883514b810425a0244d2e94c5547fd069859ca0805b6a5f8c27a9b2"), // 194
+	common.HexToHash("0x41550f021f927816df7f75b2f10e163eed1cd6bfee7592d0902e1bfb440db91e"), // 195
+	common.HexToHash("0x4a3cf60469212b09c5c2dd7993bba9fdc01f089eb01809a01b0aaebd5e33e932"), // 196
+	common.HexToHash("0x80e77bbe3fe93fb781ccc944658022d0b777d93e7458832c9fa4f6b549d2f45c"), // 197
+	common.HexToHash("0x1f808161bfde82a966c7ebb2b22b80c31c9ab39d53c127aede881cfe3822a97b"), // 198
+	common.HexToHash("0xddb2557ffffdcaf2a3043e9b6af8e77959b2125d924bba0230e798c20cddf520"), // 199
+	common.HexToHash("0x5f7000dea57c15adb47ea005ef1696aaceeef12c913638e6aad4618231d904a6"), // 200
+	common.HexToHash("0xfcaf03aec7aa27c834144bbf12fa3f90e137066d83b3bc78d60b8e7d31d87872"), // 201
+	common.HexToHash("0x8a0414d1f1991db1a0621a85c04ccc12e5805749148423c3923a0bd058e9dc87"), // 202
+	common.HexToHash("0xb852242598d28136efe7e76c51fd72f136be3d17614f6201acd90c3bd2526ab9"), // 203
+	common.HexToHash("0x9f758fb7a16a594b7e25aca3599003750091cb00fbbc0a79733ba5e83e9af623"), // 204
+	common.HexToHash("0x732575015c15ea81de3705529c2aed42ce6fe44fd3e1462c22d55d26737dd7a8"), // 205
+	common.HexToHash("0x37e302b873b50d7d565a435c4cfbee653a1f7cfd6d24486198f02f476c98b81b"), // 206
+	common.HexToHash("0xd8e38056926a033542d9e2c02f169a6abff2338cf1a07155c3cda22b9b7f5b23"), // 207
+	common.HexToHash("0x8416a284a3ea22fc47c8c1a14fc2a573a89d200e830fa040fcc5f1f536d3c8a8"), // 208
+	common.HexToHash("0x09ea95777f90940f809d3dc10cfdbd6a5328bd83878b284983c4165f56d3da6e"), // 209
+	common.HexToHash("0xcc59a3d21d1e6047f335ca145bc40d30c2f727cd9eac40df2f421af72b8b2872"), // 210
+	common.HexToHash("0x3851e104cf1a02db9409136ee54571ed899441a82808bc849e95246091552bd7"), // 211
+	common.HexToHash("0x68c226a3406b3c3bbf9112046e8d662cabeddd486d8337c0be11a9b73ba1f7c5"), // 212
+	common.HexToHash("0xa1fce06c14e7db4192923ac706d182a3db4aa589aaad1668aec48e11061bc0bb"), // 213
+	common.HexToHash("0x6076aeb4e526a24d7a8c8df731355a01ab8541c65bd52288b25d8260d43eef42"), // 214
+	common.HexToHash("0x9fb0d9a9559a9e9d0c729fbca82070b8cf49f85b1243b547687067eac211de7c"), // 215
+	common.HexToHash("0x02952c400c8dded3f2b244653695cc3d57de4c4388172cdb07693dacb426c322"), // 216
+	common.HexToHash("0x93458b724e48f84c0e3c6c86a372752043649dbc003990020df22f22f28ba8b5"), // 217
+	common.HexToHash("0xb2dffb387da16335b0ff34c4be50c0688625fca378b83892a2c9ae7e63725b0b"), // 218
+	common.HexToHash("0x35788b95f8ee09576b474930ad129149034252e04740f8b62b4616ab845dc118"), // 219
+	common.HexToHash("0xfd1a48f517ce722a97f61a4586535a9f311a01f648e42c6b07b9e1f160bc38da"), // 220
+	common.HexToHash("0x7d64b7806dc012e763629573efb9ad0cd0a324681f7e6ad76c092443ae9712b4"), // 221
+	common.HexToHash("0xc0e45eb6a45e3cb8275293c1acd12a99a25466c7ac3603f4b704da2343d39e71"), // 222
+	common.HexToHash("0x36573e16253696a3f61b25063aef03ecbddfdd5dbd0b0a83e9f892e7905813e5"), // 223
+	common.HexToHash("0x6f2e7320ad894a40ea84dba817236a592e1ba58af0d494130be42327977c68ef"), // 224
+	common.HexToHash("0x6423c7f89302e19a3aa8376ba0cea4de86e076907aa292d75eef360aa924e6c4"), // 225
+	common.HexToHash("0x1d37094915bde1960ee848ebd290ef3b8af853c98fbb0c39b7d5de522a822326"), // 226
+	common.HexToHash("0xecb1e005d8f221decf300bd0b397df78a0ee22c8a5fc8819fc868f3302a78052"), // 227
+	common.HexToHash("0x2eee8f34937906a8c36819b0be8173f10720759013767a94a278732d8065d9b7"), // 228
+	common.HexToHash("0x5fc1db7be301537275f438348b107528ea281940fccb14e63adb4b180b75292a"), // 229
+	common.HexToHash("0xac42a1984f7f68b8496ab186394bc08daba00b62a58275ae1996b878f0e61e35"), // 230
+	common.HexToHash("0xd0070500b6e68f917db1ba31bc26096e692409f668da7185fdfa0e49b3082ea0"), // 231
+	common.HexToHash("0x839d8bd0d4b3b30f4ef4810d89512ec78f806c800bc998994c5c1fa1048380ee"), // 232
+	common.HexToHash("0xdc32d749dfccbc3cb1e3f1b322c0ca1231afcd64654fa9317bdc18417635b401"), // 233
+	common.HexToHash("0xba7e5d4629b4e557453b46f7ec5d615e0366b68d49e4864e94c1f2915d627530"), // 234
+	common.HexToHash("0x5ade70e11243f76c7ba77f000a15f4f9d5e3264350f49c9e80e3595d2f56600e"), // 235
+	common.HexToHash("0x1bf42d7e1644f703425ccdee673c5cd2f37cc2652dc2b538f30c97414c5e77da"), // 236
+	common.HexToHash("0xd84172e90a1eb54191a37fcd209f7c9e255de760e7a32780519b6caaaf44f9fd"), // 237
+	common.HexToHash("0x063c0da90ec43754918adaf4915493daa84ff61fefca9b72be06a93b7afbd199"), // 238
+	common.HexToHash("0xaf0794581306bdf846fb3dc649f70bdcd0babf31269c903aa7f7114eb2053d04"), // 239
+	common.HexToHash("0x3d68073317f8b53135c9d18b24ff1182fed0967205e054109a638d16fb3daeff"), // 240
+	common.HexToHash("0xe526d0439135e712904ad78d67c65acadbf199038bac6748d7736033c3274ebc"), // 241
+	common.HexToHash("0xd84b2daa884a7f3cd0e46f159e8ce465ead7214fc971aac3d469b85e40cfc11e"), // 242
+	common.HexToHash("0x0242054cb5788c091e2cc94e9d52072566109ffeacd3a2295d01008a1aef94d4"), // 243
+	common.HexToHash("0xd79832f225cf71478e59b2475544c1d9be94dbba972f902db8696aa6fbaf5af0"), // 244
+	common.HexToHash("0x1a439e9a9eb92abc3e2e419180cc3ebd19ade7109706c6dff54c5609805b61cd"), // 245
+	common.HexToHash("0x63e7bad3b3b7e4decac580e036a51eb51b947976a49b22240a82281df2583148"), // 246
+	common.HexToHash("0x08016da52a879747921b0698e41eb5debbd8fa9b3536ddd4ea2d4558557e1261"), // 247
+	common.HexToHash("0x01fde0896f2c346ea939f08563cb5e82e573c28bd15b684e906a9bdab238e432"), // 248
+	common.HexToHash("0x9dc0864ed2dc861fe531ee921383d7316d9a603cbbdc920fe5587cbe5b2d692f"), // 249
+	common.HexToHash("0x6b184accab6d4c7bae3c30ca30c95b9c3db809d72a5ebe0e9e9202a920949216"), // 250
+	common.HexToHash("0x38ed4a46ccb38864a29e000226f6549e5fd703b9b5f8f358a310d0ef220558f8"), // 251
+	common.HexToHash("0x57610675aae5d81c74477515006fec1d08e8d94fd529594bfb57f8b36bd0ed90"), // 252
+	common.HexToHash("0x57ccf20299c6c2d16aaca22fa2a064622471111aee23e63759ebbabbe470ba32"), // 253
+	common.HexToHash("0xadde74cbc6b0867d17fa184080a04e4ee76930fa58bbe8a071652403788043a1"), // 254
+	common.HexToHash("0x7d4c36bd6a85c622ecbe98090b1c5b7e55a653170f32756a537ef84bc85b65c1"), // 255
+	common.HexToHash("0xe33ab3c1c91f998290a108a5a6f274a86c78fb0f0d0c1a8b5de63cca1dc9c71c"), // 256
+	common.HexToHash("0xf376439583ecadc1c2acc869610582a00deee494bdd71b9de7beb8dd416d88da"), // 257
+	common.HexToHash("0x2f0c8ec77805906175b73eca8aefa7442e5c4352d0ff7ccd9b8eea914fa85ffb"), // 258
+	common.HexToHash("0xf2ad4a98daf896f7a5dca3dd2c05b33eaf3291ef831b99251ba74a98be3da102"), // 259
+	common.HexToHash("0x0b00aae14f9b00b185036c342e4a6860da44c8f0d039ee6064f9eab6f9bc8d25"), // 260
+	common.HexToHash("0x670a9f3fc0057e4788980ae6b767b31ae7f67675169ac52f11cfbba349bc4bab"), // 261
+	common.HexToHash("0xa1ec46ad4f9155f4e6c3e4dc6e04abebb8f387b1c917a4773e71b7e8130b4978"), // 262
+	common.HexToHash("0x31efe5c260165485cbca449a6615802c82e9b1c0adb7ea88175d7902cde07511"), // 263
+	common.HexToHash("0xa7dcc1b04623d77bb0891111b0c383db18b31bf65d552a62fc67a111293b4e12"), // 264
+	common.HexToHash("0xa6c7ba260e1652cc4f00f359bc795a83a64daf5d22b77dac04b215dabf4d5b26"), // 265
+	common.HexToHash("0x496f26314de065444f3f628569487d30d174f6ddfad326b22b3a20f0b478dbe0"), // 266
+	common.HexToHash("0xf371a409d81649f9cdf888b58bb7d1c8a0fd0abe5c9e5cc245cea5710f144ea8"), // 267
+	common.HexToHash("0x4afba74869b8c190aca660f16e5c5b26bfc606f6d1d9ce666cacc00172a631ef"), // 268
+	common.HexToHash("0x51dcbeb47e3de0cdc9093c2a4be2307a63d9c79248f4173af6ce167f7102e7b3"), // 269
+	common.HexToHash("0xe6ce3ec6aa43110837cd4bd63706672395eacd3007b39a398033c4cfb16a3885"), // 270
+	common.HexToHash("0xdbdf253be714b0c1faa568169c94d87780e8fc542caac36916db22efae9c57e9"), // 271
+	common.HexToHash("0x2463f7a3ad42d2f924f018d2b31c9e7532de0142957fc516501e9e2fdc52db80"), // 272
+	common.HexToHash("0x9d61f4f444e696ad41621441e1dea076ab7bd37dab3daaa049a75fe0c5e3e247"), // 273
+	common.HexToHash("0x5b90d58675e2531c311a3169d84e5ff7c6a2d9a36ca4b7ea89a505349849ffcc"), // 274
+	common.HexToHash("0x1c367fe3ea01835a88f749150f6f7b69caf6a7438f9b2fc44df3fe1656517603"), // 275
+	common.HexToHash("0xb086e93773d1764369d8e2ebe6cb4c05fd76fd2873ec99aa0cedeafacf823c07"), // 276
+	common.HexToHash("0xcac27758970ac459b1d0f3bdb2ad6ef93b1d962d4b19438caf4b99240dd8d688"), // 277
+	common.HexToHash("0x40e4286a3cb999f0cab2a732b2c9a92f227774568b6520b03ef3b14993139229"), // 278
+	common.HexToHash("0x13e280b8518c87ad838e6f31590cefa81d6ba39e80430ad1c5ec9f66b06e4164"), // 279
+	common.HexToHash("0xf801ce6633871ee82f8c4ee6e90dea4faf9bd80042e9411d28e38bb305d1622a"), // 280
+	common.HexToHash("0x9cd32dd5e75b256f60c3bf0abaf7af4b1f40e542632753d9eeb28e56061282c8"), // 281
+	common.HexToHash("0xbbe7ab9ee670a9422ce439ad35aa6569244475aeda6c19a58a37d89c30334e97"), // 282
+	common.HexToHash("0x5eab3f5fc149e310c0adce56be2cb7c141ea02babce12b5ed9d204a859e341c1"), // 283
+	common.HexToHash("0x2e92888542871f8bacc45437419790e27295e3340dacbaf7cf37be4c90771f9e"), // 284
+	common.HexToHash("0x345247d42e80fb0524abc8d27aea2f6f0d6c8908ad33df5516f10edbbaea52ba"), // 285
+	common.HexToHash("0xe39b845108aeb4cf5117c5fe90ca96a14ffacccf39f8fbf24124e362cf66ca01"), // 286
+	common.HexToHash("0x4e2713783373d3c760bcae268e81043c50ccbdbaee2f0402f3b9e18d26459bf1"), // 287
+	common.HexToHash("0xc2f0b5da58cc2bc3ccae6f4770b38245d4a7a6e88ed3506624c5b5f53e31822e"), // 288
+	common.HexToHash("0xc628a924976abe53cb1735b0f9de53a871a0fd7d5ddd58651f15dc2ea12a64a3"), // 289
+	common.HexToHash("0xf22c81eac1d9d5c8d67b92d91957c8228a0a6c2ff73ddbc8f8e4b84695096b7e"), // 290
+	common.HexToHash("0xe429559d077b1e8052e759a180fd29d534853d154f2e0983991f75e6411d5f2c"), // 291
+	common.HexToHash("0x9d82a79f524b0cd87842a48c246e1cd220d9e59a9d27c4f767c63425cdf58c89"), // 292
+	common.HexToHash("0x788a39b1ec785fae4381b8cec209e78764ba5d18d7f1d27bf3c8cce55860058a"), // 293
+	common.HexToHash("0xa68a82ce8562e1d8ad80e8f0cf2db4b95345ede6b35d3747ecde284898e7f645"), // 294
+	common.HexToHash("0xd4f49448dfe861db9deb2a5640b2c48b8c0ffea960399b8dcaec303c46585708"), // 295
+	common.HexToHash("0x388f6838d10468767099e4797da8780875c901706a125564a8b58fec9a3a22c5"), // 296
+	common.HexToHash("0x93313495cd036d5e5a78bdc57c1f98727956484d0437d6627560bbe730b7f6b9"), // 297
+	common.HexToHash("0x2b1f260ff90bf357befda906151e20ca2cf4c28e61c78cc31e39451367eb4407"), // 298
+	common.HexToHash("0x58a1d140babd504bc98f917c0d13c16a8901eed4e100814f426ffc4346ec3741"), // 299
+	common.HexToHash("0x39d81f1e6927d7923ce68ef353812a6c220304ffe3ff1ae07387e6093c72d3a7"), // 300
+	common.HexToHash("0xfce2da633bc7cb345d69f0c79b86a3b56220996b36c1eeb6c5e953788fdc2066"), // 301
+	common.HexToHash("0x7d8dc56dffec0be00bce2d28be6870072eeb8ea18a39c3044e358f896c4911b3"), // 302
+	common.HexToHash("0x736886428c74706bda6520c53e4e4d3d8345da3435a364c0b70638afa471bbaa"), // 303
+	common.HexToHash("0x5ffca2b8f1f7b70426b2bf31863587d28d2e48c7f320ea0dda3143c448bf6d30"), // 304
+	common.HexToHash("0x4a8b487c28ed53b7c755932a78b40e51c29952aff4950153e7188785cd1245d4"), // 305
+	common.HexToHash("0xe0146a4473c85327e4e35b37391976f5e45dbe4af98c4d45194877ed5330fead"), // 306
+	common.HexToHash("0xea01fd40548149dd57bcf88f0938801501427c2385ff7cd821144e95fd128dab"), // 307
+	common.HexToHash("0x4436505fb5cec9dca18c8b8c58af9cd4746f147067e6e48ea64cd6b94b47d7f1"), // 308
+	common.HexToHash("0x69e60d766139cf7431a3e2430f94f440efb705ae29b145f0ea0a7e77635c21cc"), // 309
+	common.HexToHash("0xf975bd49d15462273dd0a710a6ec82fc8075da09b4d9864326bde01a3aa815b9"), // 310
+	common.HexToHash("0x62631315ddab397b0c05fd4de84043704e26419acaec8bfbbc440cc5c413a6d3"), // 311
+	common.HexToHash("0xd67bb0e1acf72da2bf1b1365f06dee960acf9fb8527db280416d7d5da8b8ed5f"), // 312
+	common.HexToHash("0x6e7a15bad46f07c5ecb645680bdbe64134646feb8987f197d737ee42bb807960"), // 313
+	common.HexToHash("0xd0f94445a24561f67c8616ef8846e027c546209873cf2f534e3c29e423b941eb"), // 314
+	common.HexToHash("0x26e98ccacc48794e3e282acc9b5d87d2b34867fc6bf7dced1de9900818b0e527"), // 315
+	common.HexToHash("0x6318a00034a57d8be7f20fcc96a40fb848f19cc5ec6cee624619fc5ae9e6d7de"), // 316
+	common.HexToHash("0xb6c2cbcd6f931cc1ff9a9338859ee432362ef69d9361c6134d3aeb7591eb055a"), // 317
+	common.HexToHash("0xc00812322be3cda2f5a4d0722ae8bda5b0d21d2ae9bdf5a21bdbeeeb8a85775b"), // 318
+	common.HexToHash("0x47465351897a06e0acbd0a1258e82f8155d1412832264fc13b90fe7cc6d9d937"), // 319
+	common.HexToHash("0x904214f8aab1dbb97c15fcb39861be95695230fcc48a3aa5fe94a6e48b4a1f79"), // 320
+	common.HexToHash("0xc66e020323c542688ccd3332b29b263e8cc59dc42d3aab29edc8bafe97cf72c5"), // 321
+	common.HexToHash("0x154f68785192b147842a2f19ffea3310de9cbb035b58ea8a31b9b108a397ac2b"), // 322
+	common.HexToHash("0xc117c0cf51cf0d4340467d22787537128accd22a12c4a289723897fb43f320bb"), // 323
+	common.HexToHash("0x98b4b0bd13eae3edde99b7bb9e86787a3d56b6f5e78c024003c14401ff4d82af"), // 324
+	common.HexToHash("0xbd90af9b292fa40da08ff6f5ac620462b668a9c49715c390b5a098bf3ec9e426"), // 325
+	common.HexToHash("0x69e96e40f5cad79778f1160b47fef18817419f5bbbd3fc623f901177777964cf"), // 326
+	common.HexToHash("0x75962de59ce8894fe8c8d12296d7f6ace488fae9951cc3d007c67f2833d6b758"), // 327
+	common.HexToHash("0xd4577ee9e370152f48debffef9ebe4a0777528963059ad6a9b9a5caa3df85f9c"), // 328
+	common.HexToHash("0x1fe982bfbc899968b5269db0c8167bc28e182e49dec02310a53c9d937ebb87cf"), // 329
+	common.HexToHash("0xb8f77b39e3f23c3dd7a26863ee969d444f5c3881d1db63e5378a9a559d3c3ca4"), // 330
+	common.HexToHash("0x587e9ee07a1921e87802a267fb9a267e17c3def83fdd9c046b2bb00f913bbf07"), // 331
+	common.HexToHash("0x777f0ba854388676a49684ee1107a93c3e4c3ba542418c0fc24676907170bb83"), // 332
+	common.HexToHash("0xacca1b0a196bd491fbfead1c195e8bd3166adb298bdb7005953b44b40fed9786"), // 333
+	common.HexToHash("0xca4c9efe4f5fa31dd8cbc091ea9bb7f86bf3bfb652a78e08ed9bf9601481107d"), // 334
+	common.HexToHash("0xc20564d2e5628e2fa2031fd258dee9fc8c73f77e58aafe6b011f94626e3e792a"), // 335
+	common.HexToHash("0x7108cd4c7f0c7813a3819177b8f8eb393f9fe0e3d19395618e5852af3bc1365c"), // 336
+	common.HexToHash("0x6670fac049c7aa6481f697c0c1defff90f3ab16046a6a4805d4b92bbd02cc95c"), // 337
+	common.HexToHash("0x684a776c6ff875c6e30cdb4c0fe50d04049b44e519f967133354667b2e4732ff"), // 338
+	common.HexToHash("0x84004eac79bb6f8be2230a63659cfc33a33c3f9f109c5c0f83babbc8eb8ed5bd"), // 339
+	common.HexToHash("0x15d2677fab55b3dd2b49a2548a45fd9e32383bb23f8de063e7d02aaa28436b14"), // 340
+	common.HexToHash("0xe7b3af9fd3f72fb316b61457a248d1e1a792bc847b1bc101d041fa6734dc93ed"), // 341
+	common.HexToHash("0x8058b261c49189628ae40eb3ea2bbe872f0ae4131f0b9d46dff46bd8fd7b6bdc"), // 342
+	common.HexToHash("0xeb80892c2c1833408f46ecb05c609e0bf6ab65bd8096d4d9a6c075c9a5fc9fc0"), // 343
+	common.HexToHash("0xd4df683fff67fc3c9431d0e050ad127b28fdc342ab0bea3ebdaf70953f99659d"), // 344
+	common.HexToHash("0x1e556d1e7cb5759c5b28a36ea24e9f2a266abc806c01ea3bbc29633ff67375c8"), // 345
+	common.HexToHash("0x55010d19bc49cd42f860272e6c959a36da508c5e9d4941d380e4331a09e137c4"), // 346
+	common.HexToHash("0xf8c8248fa1955bcf83aa1adde174035c592a67ef118de5a3a753a12bdeb6846a"), // 347
+	common.HexToHash("0x139d556d8600790150f3f129fd97f61b8bc39cdbe157038fe3fe373b85b83e30"), // 348
+	common.HexToHash("0x6e0ddda07f309f4e29fde9e2933fec9d470fa69452683ec9f52c257cd7669991"), // 349
+	common.HexToHash("0x3d6ef3c9817b5505d5f752bec9ebe6586e79fccb0f6e9faf112bbef7c8ea2211"), // 350
+	common.HexToHash("0xbfb0404fbdf3b14f58b88043c91e34bce7ed30b6506c7f2d5b211f2fa0b27429"), // 351
+	common.HexToHash("0x25f1d74feeefdfa76990fd138dc5b491cd697c9227b0db6cc2c3962d13b9acf4"), // 352
+	common.HexToHash("0x1edb13d63ed6bd3db3650aaba6713f17126c16a1bac8fcb90e9a895e9f390391"), // 353
+	common.HexToHash("0x9c440b1c268cef494cb4fbdce01823214839add45c66165b3734fdee1894489c"), // 354
+	common.HexToHash("0xd64166b86d07c4eebd94275460c203140ae6ad9e0c608cb461b675d375fdfad9"), // 355
+	common.HexToHash("0xd57501459340d33d8ce5e1873067b91deeb8da1e82595939db4da1fd4f7d93e3"), // 356
+	common.HexToHash("0xc3811c63d9024c86dcc6ff254645c42c64aec6e966bf9adb5a9a1ee7bc25eec9"), // 357
+	common.HexToHash("0x1aaabee8602c4a5905ad35b4b6bd453edccccbed49d3e5c9322eee5ea0ad19cc"), // 358
+	common.HexToHash("0xae03c5b458553752f91a5a3df1ebbedd8fc71f378a53f9c76c363a434eadccb0"), // 359
+	common.HexToHash("0x357be5f08bf06f44430f95cb4921ec2682cbf937400306bc460099e0f3d9341b"), // 360
+	common.HexToHash("0xe849ff9506e9a95325bbab3096a445e22e82c372bcfab132c321d0051cfd9fe7"), // 361
+	common.HexToHash("0xde58ceae068309e848e623d7200db8f16826d0566318658b92f6b2f295920f41"), // 362
+	common.HexToHash("0x1a4032a8399d5e8a7359bc7f4a3242fbb3d812fe84f32dff4bb9a42ed33bb182"), // 363
+	common.HexToHash("0x3a3357c0589963540f62ef8cc3564fb06b67b587ed9803f4d94247410a92d668"), // 364
+	common.HexToHash("0xd2fcc2753150658d2fb053a7a43513c3c0a33c273307950ef5d0cc6fde58ee15"), // 365
+	common.HexToHash("0xa560d0c374f795a5e327bb67c1d7c1049f7339b1fea754ea208e78c873f61912"), // 366
+	common.HexToHash("0xaff5432dac4c23f5cac9e9df5b8494356a2f205584484065c6a99689976d7664"), // 367
+	common.HexToHash("0x26d61ca3075abe3b29f4359d7e28b891a1c4e42ca44c7a5ec165d7561f859ffa"), // 368
+	common.HexToHash("0xb0e4ac89f7b47c5f6d471b6a084356820d1c6dbfd78ec22e09b961be8755e128"), // 369
+	common.HexToHash("0x3064ec20368ed9c2df091acb441b2afef270d7b8a74ed28e8d48a611404c2ac4"), // 370
+	common.HexToHash("0x9ce622844812731749ca43f8ce3517a4e10a27cb3995d3828c3708eb4ecdd566"), // 371
+	common.HexToHash("0x1c1d8be672c1f8163c3f861b807d920d9ded5c895404715a15830168dcfc0b6e"), // 372
+	common.HexToHash("0x8ba2c219cfeed271a4099412043d52da1ae06cb24c1751974ba8b75e93af24b2"), // 373
+	common.HexToHash("0x75bf17f0408256e59ead94992df7e7a83e0078b2550dc22c819fa864438c66d0"), // 374
+	common.HexToHash("0xfda371711465ec0af0afb1aaeddb414b5c75735c62cc3606e496631660149f88"), // 375
+	common.HexToHash("0xdd06bb00e43d920c5d1e83f52080f55121268cf72a358b5c3dc4ff929ceae375"), // 376
+	common.HexToHash("0x86fdee6e38a877f3fddb563952d2599d2a6ffa700c6689d00555701022ebf240"), // 377
+	common.HexToHash("0x51eed6ff8c529ac9bd63bb745214137bfe5bdea3ae0ded8891fdc8f8453c1782"), // 378
+	common.HexToHash("0xd429b5339fe1564bbc4e05c8b47279552c57c002961d632a7d931f0ceb9ec815"), // 379
+	common.HexToHash("0x3aad73d7092b7642803c2d4535e32301adc517c330724c98ec7897712de55538"), // 380
+	common.HexToHash("0xccb62cb41559ad33b27eeb926f942dd0bcd4a265fd6508fddef0e65f43548e55"), // 381
+	common.HexToHash("0x4ff9edc1b74dbc7b0fb2c8ba74a7c8d71ff83c43ff696ac4aaa292a75fe2f394"), // 382
+	common.HexToHash("0x0613997e3c620512ede1088ebd2c317ff2f8f4033262131d019ab03c0975158e"), // 383
+	common.HexToHash("0xdbbfedad4c0861aaad30b2f515fa29b705e7e6689cd1d38b049545d11f3ffc69"), // 384
+	common.HexToHash("0x829b8f584bc2538f08bcbd27097722de57a8017317e34ba9171abb31aff4640d"), // 385
+	common.HexToHash("0x4e5f58d324d1f9804ef8d1fd78f05049f11736b6a09b35da5d5fa1f8ae7ea217"), // 386
+	common.HexToHash("0xd7b3f61203700f84ced77e3885ddf7eb873054913c533ed0379734f7aab23760"), // 387
+	common.HexToHash("0x074d7179a0371a3f9d23a09573e1a392f58984418be2bb5116340024f518ab64"), // 388
+	common.HexToHash("0x0630d45ded6bdab757008836b6e6d4b80c58e9b0c80f176dda9566173d5e3bf6"), // 389
+	common.HexToHash("0x5794130ea9e433185214fb4032edbd3473499267e197d9003a6a1a5bd300b3e5"), // 390
+	common.HexToHash("0x5cc431d6d8345fde308365fa11e4c1816b62a432245b9d4d611c2ac3f18b4cdd"), // 391
+	common.HexToHash("0x4a37ee8c8cb4f75c05e23369cadeec7a6ed7386226a629794a733e0249d92d5f"), // 392
+	common.HexToHash("0x1d4d0744fcae7ace592c9aadfc604ea55dd52b42007bc1985915d1ede3a82e27"), // 393
+	common.HexToHash("0x9c872ab925445882943ec72fbdd98cd64ed4d9a3ed93888b29175b0fe5eefcb3"), // 394
+	common.HexToHash("0xba5a3734edccfae0cafde8538e6c860fa75761f64a53da3af146da44430bae7f"), // 395
+	common.HexToHash("0x5c95deab1609b7a3f94da08dc3db786d59da1a7a6de00ffd24b9478ac496c3b5"), // 396
+	common.HexToHash("0xfb6f4063d1febaee37a054b19d89db57befe8a3862cb19ab4d8e7f10a3e5196c"), // 397
+	common.HexToHash("0x9becb1f5f16ed16a9c979ddf16ed857eecb067743a0552b3509c88c31339464c"), // 398
+	common.HexToHash("0x0c74f142eed3059fb26a017d06414f5521530d1a5c3bed16340459fed2d4b4ec"), // 399
+	common.HexToHash("0x0bc6a1de67f59072719618a26dec2acdde2163ed42f1096695e99c96343530c5"), // 400
+	common.HexToHash("0x8ccc465b1df4088867abe2ae522a75ac97df95805f01ec76f69e8e981ee8b8fb"), // 401
+	common.HexToHash("0xac24c4ab8b8f4661810d70d290fabce2ef7ca75858907b50396c136ad5b4d745"), // 402
+	common.HexToHash("0x8294b08f1f6998f3f10175ed00ec3d54f72587a9e36ef4b6dc3c682a96df87a5"), // 403
+	common.HexToHash("0x58036b9be410a30560b198567c00b6ce8ebda05627291718f74ebd2a43b46e2b"), // 404
+	common.HexToHash("0xa7d14adb692f682f6b57b4fc7782304501b13f6eed35405cfec0cec63334c332"), // 405
+	common.HexToHash("0xbcbe893de0e70c82b79e00a2505e64703cf477e12b94762cbdc001b6f70966c9"), // 406
+	common.HexToHash("0x67908e8c1618d3b2ed8fbbe0306ada0456f3e1fdeb8f434ac3fb0c27a25edc89"), // 407
+	common.HexToHash("0xcb778243285d0c6492159c5905aca0c035548cefa4a225feb90dd53cbf194860"), // 408
+	common.HexToHash("0xa6070e376f8fed05bf198eff6ea6e247fc95a9226787a2b972eddfcac5317714"), // 409
+	common.HexToHash("0x33da313c13a7b9431411f1c6ffb6596a29ab578c21caab13d128f1e98c6490ef"), // 410
+	common.HexToHash("0x527b219e0c562a987dae19cf1395032d4faf0f3690265e0062234f3e6f11bcf0"), // 411
+	common.HexToHash("0xa9d9f9e5322e134e46622b688cf04c6fd72c9b4c54435d0edfb94e27cfcc405a"), // 412
+	common.HexToHash("0x704555223e815087397ad3f14e1ae1bf257f985660378abfd3bc19acd446eaa5"), // 413
+	common.HexToHash("0x0e2e7b1d4dbcfe7e5ed454013e4647a6bdff97aa8566aa882235ce3436f1845d"), // 414
+	common.HexToHash("0xdf76a31b068b816b18ff437d731f0bf3c66057bee557383071a0d6057f34aa91"), // 415
+	common.HexToHash("0xad8ee5d528aa667e98e9d1f6c2c1c6d790921eba17cde65436c016147fb88189"), // 416
+	common.HexToHash("0xb4b2c43177b54651d1d64d387d39e8c924f31ae900935437b0ac431126f2fedf"), // 417
+	common.HexToHash("0xda2de38ed40535fcedf9e71d76c23ae0739e9c81547b41384bea3d6a02d47b60"), // 418
+	common.HexToHash("0xf6b9334a3a4648522da481b84e8aa9cf73ee76a3f7b8b55cc8e28df96dcc2509"), // 419
+	common.HexToHash("0xb61abe5ae99d40fccb662d958e55d777959fa84281616d39a900b90c3faf401d"), // 420
+	common.HexToHash("0xd8497fb93bf9c69a29a17d523dd798da3a10d38a24b93e3cc527a79a09c78e10"), // 421
+	common.HexToHash("0xbf82e24d47bfa500f70cb8e07c29809137997541af11a456dc801cbf45fabf4e"), // 422
+	common.HexToHash("0x909fdd8c78d33623b33869e5b7caf4a5ab2c1940126244c0c2c3a1c4d0e33f9e"), // 423
+	common.HexToHash("0xe610c6c4433eb85618cc9bc3f39fdce0d16329afd31d16e42beeb44cd58abcad"), // 424
+	common.HexToHash("0x0810a0058f422df6b217f05ed8c47e615489adf353cf218838c5898a7dde6521"), // 425
+	common.HexToHash("0x8a1d5140deef43c2724dfd543d18c5d9487ac6623512b63408bdaa0f0d5932c1"), // 426
+	common.HexToHash("0x04b8339dfef98b9f50b1776e4ab9e66d49c6b4e8944ed38bb521a2d59c9f14be"), // 427
+	common.HexToHash("0xe51f967b18546ebb0c195432ba65b949add4d748cb3e803545a1bb84ef0ba46d"), // 428
+	common.HexToHash("0xc08566f121014bd483df555d393b72b62a3100b4b8d2a740695e0ad25c0330ce"), // 429
+	common.HexToHash("0x7f713a039f7151e3ebdb63c8bea07df9bafcc37a38681424213f54baedf8d069"), // 430
+	common.HexToHash("0xe88f8c10646ade2aecaaeb6fa6d3fc1aff9d15260516587c2bd1e238da81870f"), // 431
+	common.HexToHash("0x8add41694fafb3b29a94713e09a6a9687c7edd8ae50d4c352c8cc913cb6221b6"), // 432
+	common.HexToHash("0x838f14b3829d352a4a6bab5583ef60018dbe7f4f9e03c7367b0a20bcd6883faa"), // 433
+	common.HexToHash("0xda3478537cfbfa257eac699f4fe6b3ae4d5465304d60acaea46a23e4e529a404"), // 434
+	common.HexToHash("0xe01dddfa1316156b0df998a383f441227c1fbeac9e10b3d6cd4f669255deb1c4"), // 435
+	common.HexToHash("0xdd347f02600cac4c5e12c528819a9dfbc2db49d5378d21f14b2b2075ceb667da"), // 436
+	common.HexToHash("0xc9d86103ea9d4bf55805e5756d90bd31718e190a6ddcda85133592dbd7f1a574"), // 437
+	common.HexToHash("0x55767e10cd587259aec0a329009cac0304055170526127c7193a7442e0b72a31"), // 438
+	common.HexToHash("0x0d2375c884fc9d4b2e9488546b886bd3d96803ece9bd51623625c5cc6a1fa7e0"), // 439
+	common.HexToHash("0xaf86e4f3f4e7954cb8bcbbeffe3b3dd0070f102cdfda3851f3cd9add39d10a38"), // 440
+	common.HexToHash("0x56754ff2923d58a2c79d19316dc78ada0906a9a93372a864bab2776d5f486bfe"), // 441
+	common.HexToHash("0x7f2e700f7378b398f75d15ec956daacacb828dca3089fb8d0b06969cd5aedcdf"), // 442
+	common.HexToHash("0x0efc94c2fba1699df2a5f0a59211ef5b8fbdc944b76fd84e18638ad2b3c8421c"), // 443
+	common.HexToHash("0x7454cfe86156562cc01373ec50b321c63cc59c15e7946585b96d0ef884183167"), // 444
+	common.HexToHash("0x73112ccf3a7e880510a1344ba46927d775a8c9cef3fb270e776afefba566e920"), // 445
+	common.HexToHash("0xb2cd7be157d16b1cd2e86bcc8497597cb49212592ec02e4c74a0a9beaa2fef4d"), // 446
+	common.HexToHash("0xc8da4ab246a0dd36d2cfa5b95499c12c49febcaf8548158e3eb3d9f3a49a7e02"), // 447
+	common.HexToHash("0x28c0adff92ea1bf9a67bbf9991f24f31f82504661808869345b4d854a3bdbbe9"), // 448
+	common.HexToHash("0x69cbce39b1a5e3d2e12a5732a2aaff8ab924c211bd13d9602c3a5e5fc2189059"), // 449
+	common.HexToHash("0xbee6c7e845d48ab80e269c4ef9fa19930160943c53dc2748b67e297b4b1920d8"), // 450
+	common.HexToHash("0xc676fc0905132ea64ceb51c847b57afbd76f39c9d3408780bafef2e5b4523d0c"), // 451
+	common.HexToHash("0x07a49f40b72e22a0bdb8cf4c58d0e16ec57ce8ce1eea600df466a781238441c4"), // 452
+	common.HexToHash("0xa93e4f0d93bf36f9dfb21ec46025445d7c311a91b634b8708a57471ca41e6076"), // 453
+	common.HexToHash("0xc4e76acf49ebca581b3b17fdeb6e57956abdeea01d97e49909395d02809be668"), // 454
+	common.HexToHash("0x6e534a4f9c36329089d521015e820a92b251b609874dd73832c0f2d3642c47c6"), // 455
+	common.HexToHash("0x6c72fc31daa93f74c8d55d5d49a99f26ec7aa83cff1d58695548c4e9b874bba0"), // 456
+	common.HexToHash("0xf4e4042abffbda91f7d817b5e3f5e9db39e25e122d37a682990f28b10f731834"), // 457
+	common.HexToHash("0x3c60a8b2d14b7df643b5089ee495ee986d0f73400623a5cc269ca55d95b9bac5"), // 458
+	common.HexToHash("0xf684aa2f936a4d2add8d09d505f7975b30458ac123a77f1a8b7aa9759aa5a104"), // 459
+	common.HexToHash("0x9e700685668c81767a74cd053ed72fd5355286749bb75bf02c01bd58570c073b"), // 460
+	common.HexToHash("0x0e8b2d3af5a2235fc4eb8d0d766ae36246a341b8d29b1c36c6601984f7465701"), // 461
+	common.HexToHash("0xe21e9209ae22f493de13c34fd094a137751b2fed96277df31dae436901909b9a"), // 462
+	common.HexToHash("0xa392134bb6cd1e55006619691573354b9c5975c12884c22e1166273e9013f6bc"), // 463
+	common.HexToHash("0xd84bd768be689a160c503eb7cc844404b554c8966b6de707d7b60c8246cebee4"), // 464
+	common.HexToHash("0x784134bec9a540333cab11060203a8563f5180f15eef86b2d06881437b1901bc"), // 465
+	common.HexToHash("0x08ce9feddbb3542bd37ca38e5612f17b98eb0f96aa53c7dda504c4bc0b1066be"), // 466
+	common.HexToHash("0xd611cdd94cc668a39d4398caa8b19f4d3fb0802d3efb6da44456ffe2efdc2a8b"), // 467
+	common.HexToHash("0xe92b6f6be917754839e6a81caaf4feef61bad8a90a4c483ae9025c3be89e02b0"), // 468
+	common.HexToHash("0x0dd690cfc8de703a9b6d7147c1b55739aceb02facada5382331bcc039d681ea7"), // 469
+	common.HexToHash("0x098842a465f9137fd941900876eab96925f375432e80e95142a4c3203ef499d4"), // 470
+	common.HexToHash("0x77eee8bd5d7140ae76ceb1f5c31ae0334073ca5b744651e43646bee7a00f7f86"), // 471
+	common.HexToHash("0x9c505ea25a3b6dbd3b8fefbd329d3fadc080eabc86041ce01bdbdcd70900fceb"), // 472
+	common.HexToHash("0x05294590ca6e628023ea0da286107f7984985cabeaacd2e01b9e2ce3def36876"), // 473
+	common.HexToHash("0x8f37ada7af11276578315f1e3223789fb220b3438dd97420ce2cc464444c9ade"), // 474
+	common.HexToHash("0x7bb55cc635603c129708a4703656edfd88dea5dab8efc6d9f1d3b2b0c7f86a70"), // 475
+	common.HexToHash("0x0ff7791fd519383bd4a5a2247cf173096d83c5adc8e7ce1b5ef4882a63304314"), // 476
+	common.HexToHash("0xd9a917b3f87f75be5d485a3dd9cbceb652d37a0ecb181e5fa22677d7953bacc2"), // 477
+	common.HexToHash("0x96aca321183322ba8bae1c4f430eb6539eab8a3c98e549013b3fe6e231f090f4"), // 478
+	common.HexToHash("0xfe31289b255c7627bb32230536d022889b80e0389c3133e5d3d73507c0b42be8"), // 479
+	common.HexToHash("0x7e6c5dc7cbb224f29e1a86bf87da2f04608cdb380c1cefcd88e593a3b104cb82"), // 480
+	common.HexToHash("0xf1b9ffe33d2397c53b0c1eb7780f38f3381752faf2d4752c253115afe2af35a0"), // 481
+	common.HexToHash("0x57df0039d34365b78623cfdae4fab951cf4f475929e1a0f0b98244f1f64ff84a"), // 482
+	common.HexToHash("0xdce602967dfdc5563d86674d92937b741f38cdcd9ab049e35724e805b31da629"), // 483
+	common.HexToHash("0x12f405a776dea51fc416790e3240532c982a6fd1e5c9ab753c84d27671855bc4"), // 484
+	common.HexToHash("0x37615a1688e45cd7ce2973383ec3e329b7a1bc3a8246a8ef23ece0fbbcdcbaa1"), // 485
+	common.HexToHash("0x76506fd92448b27af99dc82e3c0b693972a63cc872d09973dd149a0fae4403ac"), // 486
+	common.HexToHash("0x3b6e2613b2f06ebc0b6f7d01b0fced92a493c944073c2c07eb5c80bb64bfd547"), // 487
+	common.HexToHash("0x9566b3ac16a3740be551ede44992572dcf054d7f121b1e012a0522a68ad83ea4"), // 488
+	common.HexToHash("0x273562ecbf50585259fb2cfad44b8ba98b165c21be918e116c43b5549042bfaa"), // 489
+	common.HexToHash("0xd936ceb4ecf66f8e3f88b8a97d149fddbc48f86df6c5eabd8272275b03a82a73"), // 490
+	common.HexToHash("0x5a9105871dbd1d700f40154e47d7494f133475b99ee4d9dcd114b1d6c7048e4b"), // 491
+	common.HexToHash("0xf99f42ade69f75a191c888768d8be447025f1c0f44739d565c4d75f508fa02b7"), // 492
+	common.HexToHash("0xb34027b8ec85e850afc3ad70ad91abf3eabf44c78dacc195d1d36c49a54d5f9e"), // 493
+	common.HexToHash("0xab6bec62b5684b2c8c4ce864a2ea26bb4f2560f5dc710699f2cc66d08e76764e"), // 494
+	common.HexToHash("0x9f85efdfbe760fc3dd47c840bc5412d29a2bbac0bc3f1d7740e1f36334147acb"), // 495
+	common.HexToHash("0x721567d7252aba9072654d4987c14df392f70e1f498e36262bae90423d3b1592"), // 496
+	common.HexToHash("0x9aa4744ff6a38e3e5fd4f9dd6c1f4269378e2e6560e11496ecde0f7e3598a9c8"), // 497
+	common.HexToHash("0xbb84aa01d4d34731cab27282bb878e8e0503ec9b1691049e375470aa540c9994"), // 498
+	common.HexToHash("0x22a1c53769115edced3cf0f5ce7ce73523db780ec7f560cb855bcadf59ef591b"), // 499
+	common.HexToHash("0x03242332525be04f39ba2b7e06a20a987acc636bc6f624e7d84086f9758a0267"), // 500
+	common.HexToHash("0x47a76f11e1d348d799bdd2b67977dce3703694efdc16679e582017844a531997"), // 501
+	common.HexToHash("0xfa629442c8704f6a8100cacd5fcbb34a0bc569330a545d101e95c8d689acb0bd"), // 502
+	common.HexToHash("0xa9241da55bae71eca7fe8d5a2d51f9b91345b3d73535c43b184581e38f6410ab"), // 503
+	common.HexToHash("0xa4007ec885a09ae0599692865069e7567f0f809a4e19de15729fcc904724a3c1"), // 504
+	common.HexToHash("0xf9895a81f4617090dff0dc4c55956bab9e42122e5d29595d517c6e756fcb867e"), // 505
+	common.HexToHash("0xf74b126e836f37faa89799e0b5ea7fe848a15dfcbc0fe774dbe065985712edd5"), // 506
+	common.HexToHash("0x795966291634b2e494a6e788c1a3053d40464889eaea5826df35aa33e42aee95"), // 507
+	common.HexToHash("0x64e2475ce9f464d921f1aa153261cd206c868a62cd9a284d89f1616566108e76"), // 508
+	common.HexToHash("0x70a2e56614b2e84dd00e91c006d507111d95a06d5f599ef966eb376fcd92635f"), // 509
+	common.HexToHash("0xc9ab686c736061b8e8245694a7ab48fb32c2e641cac8fb67e343cee5e1a084c1"), // 510
+	common.HexToHash("0xb12d2ed79f389168a662e1de8d9dcc95d751a5a3a6a53030a7297bf91389f14e"), // 511
+	common.HexToHash("0xc6ea4a2d7782fda54c1d5537d7fb1f1e8de365e99a06264b4c5851e4cf1d39e2"), // 512
+	common.HexToHash("0xe46ad0fa343b3f811d57405953e1410defa1981a2dcf864ae7b8128f39898f25"), // 513
+	common.HexToHash("0xb907180b40010d566255fc99c9ab9ea58dfeb8a87b6fe90766d171056ee793de"), // 514
+	common.HexToHash("0x062f753e600cdb964b93b18b8b84180ca69214379a223cc02395de90ef921a7e"), // 515
+	common.HexToHash("0x98d3a6bb68c8b11fff596a0c293c585fb2d98015c50c9aa6ef2e58b270f39c82"), // 516
+	common.HexToHash("0xa672aca4803a033d5a5604fa9273a459834aa8f35da8f06a80539a12ac21857f"), // 517
+}
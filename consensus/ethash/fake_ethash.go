@@ -1,19 +1,44 @@
 package ethash
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"math/big"
+	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/math"
 	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/misc"
 	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
+	"golang.org/x/crypto/sha3"
 )
 
-type Ethash struct{}
+// maxCachedEpochs is the number of verification caches kept in memory. Two are
+// enough to verify a block together with uncles from the previous epoch.
+const maxCachedEpochs = 2
+
+// Ethash is the proof-of-work consensus engine. Only light verification is
+// supported: the caches are generated on demand from the block number alone, so
+// verifying a header needs nothing beyond the header itself. LoadCache skips
+// the generation.
+type Ethash struct {
+	lock   sync.Mutex
+	caches map[uint64][]uint32 // Verification caches by epoch
+	fake   bool                // Accept any seal, for chains without proof-of-work
+}
+
+// NewFaker creates an ethash consensus engine that accepts all blocks' seal as
+// valid, but still verifies all other consensus fields.
+func NewFaker() *Ethash {
+	return &Ethash{fake: true}
+}
 
 func (ethash *Ethash) Author(header *types.Header) (common.Address, error) {
 	return header.Coinbase, nil
@@ -22,8 +47,8 @@ func (ethash *Ethash) Author(header *types.Header) (common.Address, error) {
 // CalcDifficulty is the difficulty adjustment algorithm. It returns
 // the difficulty that a new block should have when created at time
 // given the parent block's time and difficulty.
-func (ethash *Ethash) CalcDifficulty(chain consensus.ChainHeaderReader, time uint64, parent *types.Header) *big.Int {
-	return CalcDifficulty(chain.Config(), time, parent)
+func (ethash *Ethash) CalcDifficulty(chain consensus.ChainHeaderReader, time uint64, parent *types.Header) (*big.Int, error) {
+	return CalcDifficulty(chain.Config(), time, parent), nil
 }
 
 func (ethash *Ethash) Close() error {
@@ -68,20 +93,30 @@ var (
 
 // Some weird constants to avoid constant memory allocs for them.
 var (
-	big8  = big.NewInt(8)
-	big32 = big.NewInt(32)
+	big8   = big.NewInt(8)
+	big32  = big.NewInt(32)
+	two256 = new(big.Int).Exp(big.NewInt(2), big.NewInt(256), big.NewInt(0))
+)
+
+// Various error messages to mark blocks invalid. These should be private to
+// prevent engine specific errors from being referenced in the remainder of the
+// codebase, inherently breaking if the engine is swapped out. Please put common
+// error types into the consensus package.
+var (
+	errOlderBlockTime    = errors.New("timestamp older than parent")
+	errTooManyUncles     = errors.New("too many uncles")
+	errDuplicateUncle    = errors.New("duplicate uncle")
+	errUncleIsAncestor   = errors.New("uncle is ancestor")
+	errDanglingUncle     = errors.New("uncle's parent is not ancestor")
+	errInvalidDifficulty = errors.New("non-positive difficulty")
+	errInvalidMixDigest  = errors.New("invalid mix digest")
+	errInvalidPoW        = errors.New("invalid proof-of-work")
 )
 
 // AccumulateRewards credits the coinbase of the given block with the mining
 // reward. The total reward consists of the static block reward and rewards for
 // included uncles. The coinbase of each uncle block is also rewarded.
 func accumulateRewards(config *params.ChainConfig, state *state.StateDB, header *types.Header, uncles []*types.Header) {
-	// Skip block reward after the merge
-	// TODO: Remove this. It's just a crutch, this does not indicate the proof-of-stake block.
-	//       In any case, L2 does not distribute rewards.
-	if config.IsMerge(header.Number) {
-		return
-	}
 	// Select the correct block reward based on chain progression
 	blockReward := FrontierBlockReward
 	if config.IsByzantium(header.Number) {
@@ -126,22 +161,281 @@ func (ethash *Ethash) Seal(chain consensus.ChainHeaderReader, block *types.Block
 	return nil
 }
 
+// SealHash returns the hash of a block prior to it being sealed.
 func (ethash *Ethash) SealHash(header *types.Header) (hash common.Hash) {
-	return common.Hash{}
+	hasher := sha3.NewLegacyKeccak256()
+
+	enc := []interface{}{
+		header.ParentHash,
+		header.UncleHash,
+		header.Coinbase,
+		header.Root,
+		header.TxHash,
+		header.ReceiptHash,
+		header.Bloom,
+		header.Difficulty,
+		header.Number,
+		header.GasLimit,
+		header.GasUsed,
+		header.Time,
+		header.Extra,
+	}
+	if header.BaseFee != nil {
+		enc = append(enc, header.BaseFee)
+	}
+	rlp.Encode(hasher, enc)
+	hasher.Sum(hash[:0])
+	return hash
 }
 
+// VerifyHeader checks whether a header conforms to the consensus rules of the
+// stock Ethereum ethash engine.
 func (ethash *Ethash) VerifyHeader(chain consensus.ChainHeaderReader, header *types.Header, seal bool) error {
-	return nil
+	number := header.Number.Uint64()
+	parent := chain.GetHeader(header.ParentHash, number-1)
+	if parent == nil {
+		return consensus.ErrUnknownAncestor
+	}
+	return ethash.verifyHeader(chain, header, parent, false, seal)
 }
 
+// VerifyHeaders is similar to VerifyHeader, but verifies a batch of headers. The
+// headers are checked one after the other in the background, the method returns
+// a quit channel to abort the operations and a results channel to retrieve the
+// verifications in input order.
 func (ethash *Ethash) VerifyHeaders(chain consensus.ChainHeaderReader, headers []*types.Header, seals []bool) (chan<- struct{}, <-chan error) {
-	return nil, nil
+	abort := make(chan struct{})
+	results := make(chan error, len(headers))
+
+	go func() {
+		for i, header := range headers {
+			var parent *types.Header
+			if i == 0 {
+				parent = chain.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+			} else if headers[i-1].Hash() == header.ParentHash {
+				parent = headers[i-1]
+			}
+			err := consensus.ErrUnknownAncestor
+			if parent != nil {
+				err = ethash.verifyHeader(chain, header, parent, false, seals[i])
+			}
+			select {
+			case <-abort:
+				return
+			case results <- err:
+			}
+		}
+	}()
+	return abort, results
 }
 
+// VerifyUncles verifies that the given block's uncles conform to the consensus
+// rules of the stock Ethereum ethash engine.
 func (ethash *Ethash) VerifyUncles(chain consensus.ChainReader, block *types.Block) error {
+	// Verify that there are at most 2 uncles included in this block
+	if len(block.Uncles()) > maxUncles {
+		return errTooManyUncles
+	}
+	if len(block.Uncles()) == 0 {
+		return nil
+	}
+	// Gather the set of past uncles and ancestors
+	uncles, ancestors := make(map[common.Hash]struct{}), make(map[common.Hash]*types.Header)
+
+	number, parent := block.NumberU64()-1, block.ParentHash()
+	for i := 0; i < 7; i++ {
+		ancestorHeader := chain.GetHeader(parent, number)
+		if ancestorHeader == nil {
+			break
+		}
+		ancestors[parent] = ancestorHeader
+		// If the ancestor doesn't have any uncles, we don't have to iterate them
+		if ancestorHeader.UncleHash != types.EmptyUncleHash {
+			// Need to add those uncles to the banned list too
+			ancestor := chain.GetBlock(parent, number)
+			if ancestor == nil {
+				break
+			}
+			for _, uncle := range ancestor.Uncles() {
+				uncles[uncle.Hash()] = struct{}{}
+			}
+		}
+		parent, number = ancestorHeader.ParentHash, number-1
+	}
+	ancestors[block.Hash()] = block.Header()
+	uncles[block.Hash()] = struct{}{}
+
+	// Verify each of the uncles that it's recent, but not an ancestor
+	for _, uncle := range block.Uncles() {
+		// Make sure every uncle is rewarded only once
+		hash := uncle.Hash()
+		if _, ok := uncles[hash]; ok {
+			return errDuplicateUncle
+		}
+		uncles[hash] = struct{}{}
+
+		// Make sure the uncle has a valid ancestry
+		if ancestors[hash] != nil {
+			return errUncleIsAncestor
+		}
+		if ancestors[uncle.ParentHash] == nil || uncle.ParentHash == block.ParentHash() {
+			return errDanglingUncle
+		}
+		if err := ethash.verifyHeader(chain, uncle, ancestors[uncle.ParentHash], true, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// verifyHeader checks whether a header conforms to the consensus rules of the
+// stock Ethereum ethash engine.
+// See YP section 4.3.4. "Block Header Validity"
+//
+// Upstream also rejects headers too far in the future, that check depends on the
+// wall clock and is left out to keep verification deterministic.
+func (ethash *Ethash) verifyHeader(chain consensus.ChainHeaderReader, header, parent *types.Header, uncle bool, seal bool) error {
+	// Ensure that the header's extra-data section is of a reasonable size
+	if uint64(len(header.Extra)) > params.MaximumExtraDataSize {
+		return fmt.Errorf("extra-data too long: %d > %d", len(header.Extra), params.MaximumExtraDataSize)
+	}
+	if header.Time <= parent.Time {
+		return errOlderBlockTime
+	}
+	// Verify the block's difficulty based on its timestamp and parent's difficulty
+	expected := CalcDifficulty(chain.Config(), header.Time, parent)
+
+	if expected.Cmp(header.Difficulty) != 0 {
+		return fmt.Errorf("invalid difficulty: have %v, want %v", header.Difficulty, expected)
+	}
+	// Verify that the gas limit is <= 2^63-1
+	if header.GasLimit > params.MaxGasLimit {
+		return fmt.Errorf("invalid gasLimit: have %v, max %v", header.GasLimit, params.MaxGasLimit)
+	}
+	// Verify that the gasUsed is <= gasLimit
+	if header.GasUsed > header.GasLimit {
+		return fmt.Errorf("invalid gasUsed: have %d, gasLimit %d", header.GasUsed, header.GasLimit)
+	}
+	// Verify the block's gas usage and (if applicable) verify the base fee.
+	if !chain.Config().IsLondon(header.Number) {
+		// Verify BaseFee not present before EIP-1559 fork.
+		if header.BaseFee != nil {
+			return fmt.Errorf("invalid baseFee before fork: have %d, expected 'nil'", header.BaseFee)
+		}
+		if err := misc.VerifyGaslimit(parent.GasLimit, header.GasLimit); err != nil {
+			return err
+		}
+	} else if err := misc.VerifyEip1559Header(chain.Config(), parent, header); err != nil {
+		// Verify the header's EIP-1559 attributes.
+		return err
+	}
+	// Verify that the block number is parent's +1
+	if diff := new(big.Int).Sub(header.Number, parent.Number); diff.Cmp(big.NewInt(1)) != 0 {
+		return consensus.ErrInvalidNumber
+	}
+	// Verify the engine specific seal securing the block
+	if seal {
+		if err := ethash.verifySeal(header); err != nil {
+			return err
+		}
+	}
+	// If all checks passed, validate any special fields for hard forks
+	if err := misc.VerifyDAOHeaderExtraData(chain.Config(), header); err != nil {
+		return err
+	}
+	return nil
+}
+
+// verifySeal checks whether a block satisfies the PoW difficulty requirements,
+// evaluating hashimoto over the verification cache of the block's epoch.
+func (ethash *Ethash) verifySeal(header *types.Header) error {
+	if ethash.fake {
+		return nil
+	}
+	// Ensure that we have a valid difficulty for the block
+	if header.Difficulty.Sign() <= 0 {
+		return errInvalidDifficulty
+	}
+	number := header.Number.Uint64()
+
+	cache := ethash.cache(number)
+	size := datasetSize(number)
+	digest, result := hashimotoLight(size, cache, ethash.SealHash(header).Bytes(), header.Nonce.Uint64())
+
+	// Verify the calculated values against the ones provided in the header
+	if !bytes.Equal(header.MixDigest[:], digest) {
+		return errInvalidMixDigest
+	}
+	target := new(big.Int).Div(two256, header.Difficulty)
+	if new(big.Int).SetBytes(result).Cmp(target) > 0 {
+		return errInvalidPoW
+	}
+	return nil
+}
+
+// cache returns the verification cache of the epoch the given block belongs to,
+// generating it if it's not yet available.
+func (ethash *Ethash) cache(block uint64) []uint32 {
+	epoch := block / epochLength
+
+	ethash.lock.Lock()
+	defer ethash.lock.Unlock()
+
+	if cache, ok := ethash.caches[epoch]; ok {
+		return cache
+	}
+	cache := generateCache(cacheSize(block), seedHash(block))
+	ethash.addCache(epoch, cache)
+	return cache
+}
+
+// addCache keeps the verification cache of the given epoch, evicting the oldest
+// epoch to keep the memory use bounded. The lock must be held.
+func (ethash *Ethash) addCache(epoch uint64, cache []uint32) {
+	if ethash.caches == nil {
+		ethash.caches = make(map[uint64][]uint32)
+	}
+	for len(ethash.caches) >= maxCachedEpochs {
+		oldest := uint64(math.MaxUint64)
+		for e := range ethash.caches {
+			if e < oldest {
+				oldest = e
+			}
+		}
+		delete(ethash.caches, oldest)
+	}
+	ethash.caches[epoch] = cache
+}
+
+// LoadCache makes data the verification cache of the epoch the given block
+// belongs to, so it isn't generated. Generating a cache takes the bulk of the
+// instructions of a mips run, which reads it from the oracle instead. data is
+// the encoding CacheBytes returns. It must hash to the pinned hash of its epoch,
+// which costs a fraction of generating it.
+func (ethash *Ethash) LoadCache(block uint64, data []byte) error {
+	if size := cacheSize(block); uint64(len(data)) != size {
+		return fmt.Errorf("ethash cache of %d bytes, want %d", len(data), size)
+	}
+	epoch := block / epochLength
+	if epoch >= uint64(len(cacheHashes)) {
+		return fmt.Errorf("no ethash cache hash known for epoch %d", epoch)
+	}
+	if hash := crypto.Keccak256Hash(data); hash != cacheHashes[epoch] {
+		return fmt.Errorf("ethash cache hash %x, want %x", hash, cacheHashes[epoch])
+	}
+	ethash.lock.Lock()
+	defer ethash.lock.Unlock()
+
+	ethash.addCache(block/epochLength, cacheWords(data))
 	return nil
 }
 
+// CacheBytes generates the verification cache of the epoch the given block
+// belongs to, in the little endian encoding LoadCache takes.
+func CacheBytes(block uint64) []byte {
+	return generateCacheBytes(cacheSize(block), seedHash(block))
+}
+
 // CalcDifficulty is the difficulty adjustment algorithm. It returns
 // the difficulty that a new block should have when created at time
 // given the parent block's time and difficulty.
@@ -0,0 +1,233 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethash
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/misc"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// testChain is a chain reader over a fixed set of blocks.
+type testChain struct {
+	config *params.ChainConfig
+	blocks map[common.Hash]*types.Block
+}
+
+// newTestChain returns a chain of n blocks on top of a genesis block, and the
+// blocks in order of their number.
+func newTestChain(config *params.ChainConfig, n int) (*testChain, []*types.Block) {
+	chain := &testChain{config: config, blocks: make(map[common.Hash]*types.Block)}
+	blocks := []*types.Block{types.NewBlockWithHeader(&types.Header{
+		Number:     new(big.Int),
+		Difficulty: big.NewInt(131072),
+		GasLimit:   8000000,
+		UncleHash:  types.EmptyUncleHash,
+	})}
+	for i := 0; i < n; i++ {
+		blocks = append(blocks, types.NewBlockWithHeader(newTestHeader(config, blocks[i].Header(), 0)))
+	}
+	for _, block := range blocks {
+		chain.blocks[block.Hash()] = block
+	}
+	return chain, blocks
+}
+
+// newTestHeader returns a valid header of a child of parent, seed tells apart
+// siblings.
+func newTestHeader(config *params.ChainConfig, parent *types.Header, seed byte) *types.Header {
+	time := parent.Time + 10 + uint64(seed)
+	return &types.Header{
+		ParentHash: parent.Hash(),
+		UncleHash:  types.EmptyUncleHash,
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		Difficulty: CalcDifficulty(config, time, parent),
+		GasLimit:   parent.GasLimit,
+		Time:       time,
+	}
+}
+
+func (c *testChain) Config() *params.ChainConfig  { return c.config }
+func (c *testChain) CurrentHeader() *types.Header { return nil }
+
+func (c *testChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if block := c.GetBlock(hash, number); block != nil {
+		return block.Header()
+	}
+	return nil
+}
+
+func (c *testChain) GetHeaderByNumber(number uint64) *types.Header { return nil }
+
+func (c *testChain) GetHeaderByHash(hash common.Hash) *types.Header {
+	if block := c.blocks[hash]; block != nil {
+		return block.Header()
+	}
+	return nil
+}
+
+func (c *testChain) GetTd(hash common.Hash, number uint64) *big.Int { return nil }
+
+func (c *testChain) GetBlock(hash common.Hash, number uint64) *types.Block {
+	if block := c.blocks[hash]; block != nil && block.NumberU64() == number {
+		return block
+	}
+	return nil
+}
+
+// testConfig is a proof-of-work chain before London, whose gas limit follows
+// misc.VerifyGaslimit.
+var testConfig = &params.ChainConfig{
+	ChainID:        big.NewInt(1),
+	HomesteadBlock: big.NewInt(0),
+	Ethash:         new(params.EthashConfig),
+}
+
+// TestVerifyHeader checks the header rules apart from the seal.
+func TestVerifyHeader(t *testing.T) {
+	chain, blocks := newTestChain(testConfig, 1)
+	parent := blocks[1].Header()
+	tests := []struct {
+		name   string
+		modify func(header *types.Header)
+		valid  bool
+	}{
+		{"valid", func(header *types.Header) {}, true},
+		{"same timestamp", func(header *types.Header) {
+			header.Time = parent.Time
+			header.Difficulty = CalcDifficulty(testConfig, header.Time, parent)
+		}, false},
+		{"older timestamp", func(header *types.Header) {
+			header.Time = parent.Time - 1
+			header.Difficulty = CalcDifficulty(testConfig, header.Time, parent)
+		}, false},
+		{"difficulty too high", func(header *types.Header) { header.Difficulty.Add(header.Difficulty, common.Big1) }, false},
+		{"difficulty too low", func(header *types.Header) { header.Difficulty.Sub(header.Difficulty, common.Big1) }, false},
+		{"gas limit at upper bound", func(header *types.Header) {
+			header.GasLimit = parent.GasLimit + parent.GasLimit/params.GasLimitBoundDivisor - 1
+		}, true},
+		{"gas limit above upper bound", func(header *types.Header) {
+			header.GasLimit = parent.GasLimit + parent.GasLimit/params.GasLimitBoundDivisor
+		}, false},
+		{"gas limit at lower bound", func(header *types.Header) {
+			header.GasLimit = parent.GasLimit - parent.GasLimit/params.GasLimitBoundDivisor + 1
+		}, true},
+		{"gas limit below lower bound", func(header *types.Header) {
+			header.GasLimit = parent.GasLimit - parent.GasLimit/params.GasLimitBoundDivisor
+		}, false},
+		{"gas used above gas limit", func(header *types.Header) { header.GasUsed = header.GasLimit + 1 }, false},
+		{"base fee before london", func(header *types.Header) { header.BaseFee = big.NewInt(params.InitialBaseFee) }, false},
+		{"extra-data too long", func(header *types.Header) { header.Extra = make([]byte, params.MaximumExtraDataSize+1) }, false},
+		{"wrong number", func(header *types.Header) { header.Number.Add(header.Number, common.Big1) }, false},
+	}
+	for _, test := range tests {
+		header := newTestHeader(testConfig, parent, 0)
+		test.modify(header)
+		err := NewFaker().VerifyHeader(chain, header, true)
+		if test.valid && err != nil {
+			t.Errorf("%s: valid header rejected: %v", test.name, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("%s: invalid header accepted", test.name)
+		}
+	}
+}
+
+// TestVerifyUncles checks the uncle rules on block 10 of a chain, whose
+// ancestors include an uncle in block 9.
+func TestVerifyUncles(t *testing.T) {
+	chain, blocks := newTestChain(testConfig, 8)
+	// block 9 includes a sibling of block 8
+	included := newTestHeader(testConfig, blocks[7].Header(), 1)
+	header9 := newTestHeader(testConfig, blocks[8].Header(), 0)
+	header9.UncleHash = types.CalcUncleHash([]*types.Header{included})
+	blocks = append(blocks, types.NewBlockWithHeader(header9).WithBody(nil, []*types.Header{included}))
+	chain.blocks[blocks[9].Hash()] = blocks[9]
+
+	var (
+		// uncle is a sibling of block 9, old a child of block 2, beyond the
+		// seven generations of ancestors
+		uncle  = newTestHeader(testConfig, blocks[8].Header(), 1)
+		uncle2 = newTestHeader(testConfig, blocks[3].Header(), 1)
+		old    = newTestHeader(testConfig, blocks[2].Header(), 1)
+		// a sibling of the block itself
+		sibling = newTestHeader(testConfig, blocks[9].Header(), 1)
+	)
+	bad := types.CopyHeader(uncle)
+	bad.Time = blocks[8].Time()
+	bad.Difficulty = CalcDifficulty(testConfig, bad.Time, blocks[8].Header())
+	unsealed := types.CopyHeader(uncle2)
+	unsealed.Nonce = types.EncodeNonce(1)
+
+	tests := []struct {
+		name   string
+		uncles []*types.Header
+		engine *Ethash
+		err    error
+	}{
+		{"no uncles", nil, NewFaker(), nil},
+		{"two uncles", []*types.Header{uncle, uncle2}, NewFaker(), nil},
+		{"too many uncles", []*types.Header{uncle, uncle2, newTestHeader(testConfig, blocks[4].Header(), 1)}, NewFaker(), errTooManyUncles},
+		{"duplicate uncle", []*types.Header{uncle, uncle}, NewFaker(), errDuplicateUncle},
+		{"uncle of an ancestor", []*types.Header{included}, NewFaker(), errDuplicateUncle},
+		{"ancestor as uncle", []*types.Header{blocks[8].Header()}, NewFaker(), errUncleIsAncestor},
+		{"uncle too old", []*types.Header{old}, NewFaker(), errDanglingUncle},
+		{"sibling as uncle", []*types.Header{sibling}, NewFaker(), errDanglingUncle},
+		{"invalid uncle header", []*types.Header{bad}, NewFaker(), errOlderBlockTime},
+		{"bad uncle seal", []*types.Header{unsealed}, new(Ethash), errInvalidMixDigest},
+	}
+	for _, test := range tests {
+		header := newTestHeader(testConfig, blocks[9].Header(), 0)
+		header.UncleHash = types.CalcUncleHash(test.uncles)
+		block := types.NewBlockWithHeader(header).WithBody(nil, test.uncles)
+		if err := test.engine.VerifyUncles(chain, block); !errors.Is(err, test.err) {
+			t.Errorf("%s: have %v, want %v", test.name, err, test.err)
+		}
+	}
+}
+
+// TestVerifyHeaderDAO checks that the extra-data of the blocks following the DAO
+// fork is held to the side of the fork the chain took.
+func TestVerifyHeaderDAO(t *testing.T) {
+	tests := []struct {
+		support bool
+		extra   []byte
+		err     error
+	}{
+		{true, params.DAOForkBlockExtra, nil},
+		{true, nil, misc.ErrBadProDAOExtra},
+		{false, params.DAOForkBlockExtra, misc.ErrBadNoDAOExtra},
+		{false, nil, nil},
+	}
+	for i, test := range tests {
+		config := *testConfig
+		config.DAOForkBlock = big.NewInt(2)
+		config.DAOForkSupport = test.support
+		chain, blocks := newTestChain(&config, 1)
+
+		header := newTestHeader(&config, blocks[1].Header(), 0)
+		header.Extra = test.extra
+		if err := NewFaker().VerifyHeader(chain, header, true); !errors.Is(err, test.err) {
+			t.Errorf("test %d: have %v, want %v", i, err, test.err)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package misc
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/oracle"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// TestDAODrainList checks the accounts drained at the DAO fork: the DAO, its
+// extra balance account and 57 child DAOs with theirs.
+func TestDAODrainList(t *testing.T) {
+	list := params.DAODrainList()
+	if len(list) != 116 {
+		t.Fatalf("drain list length: have %d, want 116", len(list))
+	}
+	seen := make(map[common.Address]bool)
+	for _, addr := range list {
+		if seen[addr] {
+			t.Errorf("duplicate drained account %x", addr)
+		}
+		if addr == params.DAORefundContract {
+			t.Errorf("refund contract is drained")
+		}
+		seen[addr] = true
+	}
+	if first := common.HexToAddress("0xd4fe7bc31cedb7bfb8a345f31e668033056b2728"); list[0] != first {
+		t.Errorf("first drained account: have %x, want %x", list[0], first)
+	}
+	if dao := common.HexToAddress("0xbb9bc244d798123fde783fcc1c72d3bb8c189413"); list[114] != dao {
+		t.Errorf("DAO account: have %x, want %x", list[114], dao)
+	}
+	if extra := common.HexToAddress("0x807640a13483f8ac783c557fcdf27be11ea4ac7a"); list[115] != extra {
+		t.Errorf("DAO extra balance account: have %x, want %x", list[115], extra)
+	}
+}
+
+// TestApplyDAOHardFork checks that the balances of the drained accounts, and
+// only those, end up in the refund contract.
+func TestApplyDAOHardFork(t *testing.T) {
+	oracle.UseMemory()
+	statedb, err := state.New(types.EmptyRootHash, state.NewDatabase(types.Header{Number: new(big.Int), Root: types.EmptyRootHash}), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var (
+		list  = params.DAODrainList()
+		other = common.HexToAddress("0x0123")
+		want  = new(big.Int)
+	)
+	for i, addr := range list {
+		statedb.AddBalance(addr, big.NewInt(int64(i+1)))
+		want.Add(want, big.NewInt(int64(i+1)))
+	}
+	statedb.AddBalance(other, big.NewInt(1000))
+	ApplyDAOHardFork(statedb)
+
+	if have := statedb.GetBalance(params.DAORefundContract); have.Cmp(want) != 0 {
+		t.Errorf("refund contract balance: have %v, want %v", have, want)
+	}
+	for _, addr := range list {
+		if have := statedb.GetBalance(addr); have.Sign() != 0 {
+			t.Errorf("drained account %x balance: have %v, want 0", addr, have)
+		}
+	}
+	if have := statedb.GetBalance(other); have.Cmp(big.NewInt(1000)) != 0 {
+		t.Errorf("other account balance: have %v, want 1000", have)
+	}
+}
+
+// TestVerifyDAOHeaderExtraData checks the extra-data of the blocks in the DAO
+// fork range on both sides of the fork.
+func TestVerifyDAOHeaderExtraData(t *testing.T) {
+	pro := &params.ChainConfig{DAOForkBlock: big.NewInt(10), DAOForkSupport: true}
+	no := &params.ChainConfig{DAOForkBlock: big.NewInt(10)}
+	tests := []struct {
+		config *params.ChainConfig
+		number int64
+		extra  []byte
+		err    error
+	}{
+		{pro, 9, nil, nil},
+		{pro, 10, params.DAOForkBlockExtra, nil},
+		{pro, 10, nil, ErrBadProDAOExtra},
+		{pro, 19, []byte("dao-soft-fork"), ErrBadProDAOExtra},
+		{pro, 20, nil, nil},
+		{no, 10, nil, nil},
+		{no, 10, params.DAOForkBlockExtra, ErrBadNoDAOExtra},
+		{no, 20, params.DAOForkBlockExtra, nil},
+		{&params.ChainConfig{}, 10, nil, nil},
+	}
+	for i, test := range tests {
+		header := &types.Header{Number: big.NewInt(test.number), Extra: test.extra}
+		if err := VerifyDAOHeaderExtraData(test.config, header); err != test.err {
+			t.Errorf("test %d: have %v, want %v", i, err, test.err)
+		}
+	}
+}
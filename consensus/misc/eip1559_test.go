@@ -0,0 +1,97 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package misc
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// forkedConfig returns a London chain whose base fee parameters change at
+// blocks 10 and 20.
+func forkedConfig() *params.ChainConfig {
+	config := *params.TestChainConfig
+	config.EIP1559 = &params.EIP1559Config{
+		Forks: []params.EIP1559Fork{
+			{Block: big.NewInt(10), ElasticityMultiplier: 4, BaseFeeChangeDenominator: 50},
+			{Block: big.NewInt(20), BaseFeeChangeDenominator: 100},
+		},
+	}
+	return &config
+}
+
+// TestCalcBaseFeeFork checks that the base fee of a block follows the
+// parameters in effect at the block, not at its parent.
+func TestCalcBaseFeeFork(t *testing.T) {
+	config := forkedConfig()
+	tests := []struct {
+		parentNumber  int64
+		parentGasUsed uint64
+		expected      int64
+	}{
+		// mainnet parameters, the gas target is 10M
+		{8, 10000000, 1000000000},
+		{8, 9000000, 987500000},
+		{8, 11000000, 1012500000},
+		// block 10 uses the first fork, the gas target is 5M
+		{9, 5000000, 1000000000},
+		{9, 10000000, 1020000000},
+		{9, 4000000, 996000000},
+		{10, 10000000, 1020000000},
+		// block 20 only changes the denominator
+		{19, 10000000, 1010000000},
+		{19, 4000000, 998000000},
+	}
+	for i, test := range tests {
+		parent := &types.Header{
+			Number:   big.NewInt(test.parentNumber),
+			GasLimit: 20000000,
+			GasUsed:  test.parentGasUsed,
+			BaseFee:  big.NewInt(params.InitialBaseFee),
+		}
+		if have, want := CalcBaseFee(config, parent), big.NewInt(test.expected); have.Cmp(want) != 0 {
+			t.Errorf("test %d: have %d want %d", i, have, want)
+		}
+	}
+}
+
+// TestVerifyEip1559HeaderFork checks that a block at a fork is verified with
+// the new parameters.
+func TestVerifyEip1559HeaderFork(t *testing.T) {
+	config := forkedConfig()
+	parent := &types.Header{
+		Number:   big.NewInt(9),
+		GasLimit: 20000000,
+		GasUsed:  10000000,
+		BaseFee:  big.NewInt(params.InitialBaseFee),
+	}
+	header := &types.Header{
+		Number:   big.NewInt(10),
+		GasLimit: 20000000,
+		BaseFee:  big.NewInt(1020000000),
+	}
+	if err := VerifyEip1559Header(config, parent, header); err != nil {
+		t.Fatalf("header at the fork rejected: %v", err)
+	}
+	header.BaseFee = big.NewInt(1012500000) // the base fee under the old parameters
+	if err := VerifyEip1559Header(config, parent, header); err == nil {
+		t.Fatal("header with the base fee of the old parameters accepted")
+	}
+}
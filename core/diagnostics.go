@@ -0,0 +1,96 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// CompareReceipts compares the receipts of a processed block with reference
+// receipts field by field. It returns the index of the first transaction whose
+// receipt differs with a description of the difference, or -1 and nil if all
+// of them match.
+func CompareReceipts(have, want types.Receipts) (int, error) {
+	for i := 0; i < len(have) && i < len(want); i++ {
+		if err := compareReceipt(have[i], want[i]); err != nil {
+			return i, err
+		}
+	}
+	if len(have) != len(want) {
+		i := len(have)
+		if len(want) < i {
+			i = len(want)
+		}
+		return i, fmt.Errorf("receipt count mismatch: have %d, want %d", len(have), len(want))
+	}
+	return -1, nil
+}
+
+func compareReceipt(have, want *types.Receipt) error {
+	if have.Type != want.Type {
+		return fmt.Errorf("type mismatch: have %d, want %d", have.Type, want.Type)
+	}
+	if !bytes.Equal(have.PostState, want.PostState) {
+		return fmt.Errorf("post state mismatch: have %x, want %x", have.PostState, want.PostState)
+	}
+	// the status is implied by the post state before byzantium
+	if len(want.PostState) == 0 && have.Status != want.Status {
+		return fmt.Errorf("status mismatch: have %d, want %d", have.Status, want.Status)
+	}
+	if have.GasUsed != want.GasUsed {
+		return fmt.Errorf("gas used mismatch: have %d, want %d", have.GasUsed, want.GasUsed)
+	}
+	if have.CumulativeGasUsed != want.CumulativeGasUsed {
+		return fmt.Errorf("cumulative gas used mismatch: have %d, want %d", have.CumulativeGasUsed, want.CumulativeGasUsed)
+	}
+	if have.ContractAddress != want.ContractAddress {
+		return fmt.Errorf("contract address mismatch: have %s, want %s", have.ContractAddress, want.ContractAddress)
+	}
+	if len(have.Logs) != len(want.Logs) {
+		return fmt.Errorf("log count mismatch: have %d, want %d", len(have.Logs), len(want.Logs))
+	}
+	for i := range have.Logs {
+		if err := compareLog(have.Logs[i], want.Logs[i]); err != nil {
+			return fmt.Errorf("log %d: %v", i, err)
+		}
+	}
+	if have.Bloom != want.Bloom {
+		return fmt.Errorf("bloom mismatch: have %x, want %x", have.Bloom, want.Bloom)
+	}
+	return nil
+}
+
+func compareLog(have, want *types.Log) error {
+	if have.Address != want.Address {
+		return fmt.Errorf("address mismatch: have %s, want %s", have.Address, want.Address)
+	}
+	if len(have.Topics) != len(want.Topics) {
+		return fmt.Errorf("topic count mismatch: have %d, want %d", len(have.Topics), len(want.Topics))
+	}
+	for i := range have.Topics {
+		if have.Topics[i] != want.Topics[i] {
+			return fmt.Errorf("topic %d mismatch: have %s, want %s", i, have.Topics[i], want.Topics[i])
+		}
+	}
+	if !bytes.Equal(have.Data, want.Data) {
+		return fmt.Errorf("data mismatch: have %x, want %x", have.Data, want.Data)
+	}
+	return nil
+}
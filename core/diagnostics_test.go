@@ -0,0 +1,115 @@
+//go:build !mips
+// +build !mips
+
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/oracle"
+)
+
+func testReceipts() types.Receipts {
+	log := &types.Log{
+		Address: common.HexToAddress("0x10"),
+		Topics:  []common.Hash{common.HexToHash("0x01"), common.HexToHash("0x02")},
+		Data:    []byte{0x03},
+	}
+	receipts := types.Receipts{
+		{Type: types.LegacyTxType, Status: types.ReceiptStatusSuccessful, GasUsed: 21000, CumulativeGasUsed: 21000},
+		{Type: types.DynamicFeeTxType, Status: types.ReceiptStatusSuccessful, GasUsed: 30000, CumulativeGasUsed: 51000, Logs: []*types.Log{log}},
+		{Type: types.LegacyTxType, Status: types.ReceiptStatusFailed, GasUsed: 60000, CumulativeGasUsed: 111000, ContractAddress: common.HexToAddress("0x20")},
+	}
+	for _, r := range receipts {
+		r.Bloom = types.CreateBloom(types.Receipts{r})
+	}
+	return receipts
+}
+
+func TestCompareReceipts(t *testing.T) {
+	tests := []struct {
+		modify func(types.Receipts) types.Receipts
+		index  int
+		err    string
+	}{
+		{func(r types.Receipts) types.Receipts { return r }, -1, ""},
+		{func(r types.Receipts) types.Receipts { r[1].Type = types.AccessListTxType; return r }, 1, "type mismatch"},
+		{func(r types.Receipts) types.Receipts { r[2].Status = types.ReceiptStatusSuccessful; return r }, 2, "status mismatch"},
+		{func(r types.Receipts) types.Receipts { r[0].GasUsed++; return r }, 0, "gas used mismatch"},
+		{func(r types.Receipts) types.Receipts { r[1].CumulativeGasUsed++; return r }, 1, "cumulative gas used mismatch"},
+		{func(r types.Receipts) types.Receipts { r[2].ContractAddress = common.Address{}; return r }, 2, "contract address mismatch"},
+		{func(r types.Receipts) types.Receipts { r[1].Logs = nil; return r }, 1, "log count mismatch"},
+		{func(r types.Receipts) types.Receipts { r[1].Logs[0].Topics[1] = common.Hash{}; return r }, 1, "log 0: topic 1 mismatch"},
+		{func(r types.Receipts) types.Receipts { r[1].Logs[0].Data = nil; return r }, 1, "log 0: data mismatch"},
+		{func(r types.Receipts) types.Receipts { r[0].Bloom = types.Bloom{1}; return r }, 0, "bloom mismatch"},
+		{func(r types.Receipts) types.Receipts { return r[:2] }, 2, "receipt count mismatch"},
+		// before byzantium the post state stands for the status
+		{func(r types.Receipts) types.Receipts { r[0].PostState = []byte{1}; return r }, 0, "post state mismatch"},
+	}
+	for i, test := range tests {
+		index, err := CompareReceipts(test.modify(testReceipts()), testReceipts())
+		if index != test.index {
+			t.Errorf("test %d: index: have %d, want %d", i, index, test.index)
+		}
+		if (err == nil) != (test.err == "") || err != nil && !strings.HasPrefix(err.Error(), test.err) {
+			t.Errorf("test %d: error: have %v, want %q", i, err, test.err)
+		}
+	}
+	// the status isn't compared when the post state is set
+	have, want := testReceipts(), testReceipts()
+	have[0].PostState, want[0].PostState = []byte{1}, []byte{1}
+	have[0].Status = types.ReceiptStatusFailed
+	if index, err := CompareReceipts(have, want); index != -1 || err != nil {
+		t.Errorf("pre-byzantium status: have %d %v, want -1 nil", index, err)
+	}
+}
+
+// TestCompareReferenceReceipt checks a receipt as a node returns it against
+// the receipt of the processed transaction.
+func TestCompareReferenceReceipt(t *testing.T) {
+	want := testReceipts()[1]
+	enc, err := json.Marshal(map[string]interface{}{
+		"type":              "0x2",
+		"status":            "0x1",
+		"cumulativeGasUsed": "0xc738",
+		"gasUsed":           "0x7530",
+		"logsBloom":         want.Bloom,
+		"logs": []map[string]interface{}{{
+			"address": want.Logs[0].Address,
+			"topics":  want.Logs[0].Topics,
+			"data":    "0x03",
+		}},
+		"contractAddress": nil,
+		"transactionHash": common.HexToHash("0xaa"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res oracle.ReceiptResult
+	if err := json.Unmarshal(enc, &res); err != nil {
+		t.Fatal(err)
+	}
+	if index, err := CompareReceipts(types.Receipts{want}, types.Receipts{res.ToReceipt()}); err != nil {
+		t.Errorf("receipt %d: %v", index, err)
+	}
+}
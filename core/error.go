@@ -32,6 +32,10 @@ var (
 	// ErrNoGenesis is returned when there is no Genesis Block.
 	ErrNoGenesis = errors.New("genesis not found in chain")
 
+	// ErrBadAncestorHashes is recorded when the ancestor hashes preimage doesn't
+	// list the ancestors of the parent block.
+	ErrBadAncestorHashes = errors.New("bad ancestor hashes")
+
 	errSideChainReceipts = errors.New("side blocks can't be accepted as ancient chain data")
 )
 
@@ -35,6 +35,21 @@ type ChainContext interface {
 	GetHeader(common.Hash, uint64) *types.Header
 }
 
+// ancestorHashReader is implemented by chains that can look up the hashes of
+// recent ancestors of a header without reading every header in between.
+type ancestorHashReader interface {
+	// GetAncestorHash returns the hash of the ancestor of ref at number n, the
+	// boolean is false if the hash isn't known this way.
+	GetAncestorHash(ref *types.Header, n uint64) (common.Hash, bool)
+}
+
+// missingHeaderRecorder is implemented by chains that remember the headers
+// they were asked for but couldn't provide.
+type missingHeaderRecorder interface {
+	// MissingHeader records that the header of hash at number wasn't found.
+	MissingHeader(hash common.Hash, number uint64)
+}
+
 // NewEVMBlockContext creates a new context for use in the EVM.
 func NewEVMBlockContext(header *types.Header, chain ChainContext, author *common.Address) vm.BlockContext {
 	var (
@@ -91,6 +106,12 @@ func GetHashFn(ref *types.Header, chain ChainContext) func(n uint64) common.Hash
 		if idx := ref.Number.Uint64() - n - 1; idx < uint64(len(cache)) {
 			return cache[idx]
 		}
+		// Skip the header walk if the chain knows the ancestor hashes
+		if reader, ok := chain.(ancestorHashReader); ok {
+			if hash, ok := reader.GetAncestorHash(ref, n); ok {
+				return hash
+			}
+		}
 		// No luck in the cache, but we can start iterating from the last element we already know
 		lastKnownHash := cache[len(cache)-1]
 		lastKnownNumber := ref.Number.Uint64() - uint64(len(cache))
@@ -98,6 +119,10 @@ func GetHashFn(ref *types.Header, chain ChainContext) func(n uint64) common.Hash
 		for {
 			header := chain.GetHeader(lastKnownHash, lastKnownNumber)
 			if header == nil {
+				// the zero hash BLOCKHASH gets then is wrong, let the chain know
+				if recorder, ok := chain.(missingHeaderRecorder); ok {
+					recorder.MissingHeader(lastKnownHash, lastKnownNumber)
+				}
 				break
 			}
 			cache = append(cache, header.ParentHash)
@@ -1,31 +1,84 @@
 package core
 
 import (
-	"log"
+	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/clique"
 	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/stateless"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/oracle"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
 )
 
+// headerCacheLimit is the number of headers kept in memory, enough for the
+// BLOCKHASH window and the consensus engines' ancestor lookups.
+const headerCacheLimit = 512
+
+// BlockChain is a read-only view of the chain ending at the parent of the block
+// being processed. Headers are read from the preimage oracle by hash, ancestors
+// of the parent are found by walking the ParentHash links.
 type BlockChain struct {
-	// TODO: write stub BlockChain
 	chainConfig *params.ChainConfig // Chain & network configuration
 	engine      consensus.Engine
 	lastBlock   *types.Header
+	lastTd      *big.Int // Total difficulty of lastBlock, nil if unknown
+
+	ancestorsHash common.Hash   // Hash of the ancestor hashes preimage, zero if not available
+	ancestors     []common.Hash // Ancestor hashes of the block being processed, newest first
+
+	headerCache map[common.Hash]*types.Header // Recently read headers
+	numberCache map[uint64]common.Hash        // Canonical hashes of the recently read ancestors
+
+	witness *stateless.Witness // Records the headers read, nil if not recording
+
+	err error // First failure to read a part of the chain the block needs
+}
+
+func NewBlockChain(chainConfig *params.ChainConfig, parent *types.Header) *BlockChain {
+	var engine consensus.Engine
+	if chainConfig.Clique != nil {
+		engine = clique.New(chainConfig.Clique)
+	} else {
+		engine = &ethash.Ethash{}
+	}
+	return NewBlockChainWithEngine(chainConfig, parent, beacon.New(engine))
 }
 
-func NewBlockChain(parent *types.Header) *BlockChain {
-	return &BlockChain{
-		chainConfig: params.MainnetChainConfig,
-		engine:      &ethash.Ethash{},
+// NewBlockChainWithEngine is NewBlockChain with the consensus engine chosen by
+// the caller instead of derived from the chain config.
+func NewBlockChainWithEngine(chainConfig *params.ChainConfig, parent *types.Header, engine consensus.Engine) *BlockChain {
+	bc := &BlockChain{
+		chainConfig: chainConfig,
+		engine:      engine,
 		lastBlock:   parent,
 	}
+	bc.resetCache()
+	return bc
+}
+
+// resetCache drops all cached headers but the parent block.
+func (bc *BlockChain) resetCache() {
+	hash := bc.lastBlock.Hash()
+	bc.headerCache = map[common.Hash]*types.Header{hash: bc.lastBlock}
+	bc.numberCache = map[uint64]common.Hash{bc.lastBlock.Number.Uint64(): hash}
+}
+
+// cacheHeader remembers a header, canonical marks it as an ancestor of the
+// parent block.
+func (bc *BlockChain) cacheHeader(hash common.Hash, header *types.Header, canonical bool) {
+	if len(bc.headerCache) >= headerCacheLimit {
+		bc.resetCache()
+	}
+	bc.headerCache[hash] = header
+	if canonical {
+		bc.numberCache[header.Number.Uint64()] = hash
+	}
 }
 
 // Config retrieves the chain's fork configuration.
@@ -34,45 +87,214 @@ func (bc *BlockChain) Config() *params.ChainConfig { return bc.chainConfig }
 // Engine retrieves the blockchain's consensus engine.
 func (bc *BlockChain) Engine() consensus.Engine { return bc.engine }
 
+// readHeader retrieves a block header from the preimage oracle by hash. It
+// returns nil if the preimage is missing or isn't a header.
+func (bc *BlockChain) readHeader(hash common.Hash) *types.Header {
+	if header, ok := bc.headerCache[hash]; ok {
+		if bc.witness != nil {
+			bc.witness.AddHeader(header)
+		}
+		return header
+	}
+	oracle.PrefetchHeader(hash)
+	data := oracle.Preimage(hash)
+	if len(data) == 0 {
+		return nil
+	}
+	header := new(types.Header)
+	if err := rlp.DecodeBytes(data, header); err != nil {
+		return nil
+	}
+	bc.cacheHeader(hash, header, false)
+	if bc.witness != nil {
+		bc.witness.AddHeader(header)
+	}
+	return header
+}
+
 // GetHeader retrieves a block header from the database by hash and number,
 // caching it if found.
 func (bc *BlockChain) GetHeader(hash common.Hash, number uint64) *types.Header {
-	if hash == bc.lastBlock.Hash() {
-		return bc.lastBlock
+	header := bc.readHeader(hash)
+	if header == nil || header.Number.Uint64() != number {
+		return nil
 	}
-	oracle.PrefetchBlock(big.NewInt(int64(number)), true, nil)
+	return header
+}
 
-	var ret types.Header
-	err := rlp.DecodeBytes(oracle.Preimage(hash), &ret)
-	if err != nil {
-		log.Fatal(err)
+// GetBlock retrieves a block from the database by hash and number. Only the
+// header and the uncles are available, the transactions are left empty.
+func (bc *BlockChain) GetBlock(hash common.Hash, number uint64) *types.Block {
+	header := bc.GetHeader(hash, number)
+	if header == nil {
+		return nil
 	}
+	var uncles []*types.Header
+	if header.UncleHash != types.EmptyUncleHash {
+		oracle.PrefetchUncles(big.NewInt(int64(number)))
+		data := oracle.Preimage(header.UncleHash)
+		if err := rlp.DecodeBytes(data, &uncles); err != nil {
+			bc.setError(fmt.Errorf("bad uncles of block %d %x: %v", number, hash, err))
+			return nil
+		}
+		if bc.witness != nil {
+			bc.witness.AddUncles(data)
+		}
+	}
+	return types.NewBlockWithHeader(header).WithBody(nil, uncles)
+}
+
+// setError remembers the first non-nil error it is called with.
+func (bc *BlockChain) setError(err error) {
+	if bc.err == nil {
+		bc.err = err
+	}
+}
+
+// Error returns the first failure to read a part of the chain, such as the
+// uncles of an ancestor, that the block needed. The lookup itself returns
+// nothing then, so the result of processing the block can't be trusted.
+func (bc *BlockChain) Error() error {
+	return bc.err
+}
 
-	return &ret
+// MissingHeader records a header the block needed but the oracle didn't have,
+// like an ancestor BLOCKHASH reads, as the error of the chain.
+func (bc *BlockChain) MissingHeader(hash common.Hash, number uint64) {
+	bc.setError(fmt.Errorf("missing header of block %d %x", number, hash))
 }
 
+// CurrentHeader retrieves the current head header of the canonical chain, that
+// is the parent of the block being processed.
 func (bc *BlockChain) CurrentHeader() *types.Header {
-	log.Fatal("CurrentHeader")
-	// this right?
 	return bc.lastBlock
 }
 
 // GetHeaderByHash retrieves a block header from the database by hash, caching it if
 // found.
 func (bc *BlockChain) GetHeaderByHash(hash common.Hash) *types.Header {
-	log.Fatal("GetHeaderByHash", hash)
-	return nil
+	return bc.readHeader(hash)
 }
 
 // GetHeaderByNumber retrieves a block header from the database by number,
 // caching it (associated with its hash) if found.
 func (bc *BlockChain) GetHeaderByNumber(number uint64) *types.Header {
-	log.Fatal("GetHeaderByNumber", number)
-	return nil
+	head := bc.lastBlock.Number.Uint64()
+	if number > head {
+		return nil
+	}
+	// Start from the closest known ancestor at or above the requested number
+	header := bc.lastBlock
+	for n := number; n < head; n++ {
+		if hash, ok := bc.numberCache[n]; ok {
+			if cached := bc.readHeader(hash); cached != nil {
+				header = cached
+				break
+			}
+		}
+	}
+	for header.Number.Uint64() > number {
+		if header = bc.readParent(header); header == nil {
+			return nil
+		}
+	}
+	return header
+}
+
+// readParent retrieves the parent of an ancestor of the parent block and marks
+// it canonical.
+func (bc *BlockChain) readParent(header *types.Header) *types.Header {
+	parent := bc.GetHeader(header.ParentHash, header.Number.Uint64()-1)
+	if parent != nil {
+		bc.cacheHeader(header.ParentHash, parent, true)
+	}
+	return parent
+}
+
+// StartWitness records every header the chain reads from now on into w.
+// Ancestor hashes are found by walking the headers while recording, so the
+// witness has the headers BLOCKHASH needs.
+func (bc *BlockChain) StartWitness(w *stateless.Witness) {
+	bc.witness = w
+}
+
+// SetAncestorHashes sets the hash of a preimage listing the hashes of the parent
+// block and its ancestors, newest first. The preimage is only read when a hash
+// deeper than the parent is requested.
+func (bc *BlockChain) SetAncestorHashes(hash common.Hash) {
+	bc.ancestorsHash = hash
+	bc.ancestors = nil
+}
+
+// GetAncestorHash returns the hash of the ancestor at number n of a child of the
+// parent block from the ancestor hashes preimage. The list must start at the
+// parent block and cover up to 256 blocks. Its hash is committed to by the
+// inputs, which the on-chain side derives from BLOCKHASH, so the entries aren't
+// checked against the headers. A list of the wrong length or start is recorded
+// as the error of the chain.
+func (bc *BlockChain) GetAncestorHash(ref *types.Header, n uint64) (common.Hash, bool) {
+	// a witness needs the headers, so they are walked instead
+	if bc.witness != nil || bc.ancestorsHash == (common.Hash{}) || ref.ParentHash != bc.lastBlock.Hash() {
+		return common.Hash{}, false
+	}
+	if bc.ancestors == nil && !bc.readAncestorHashes() {
+		return common.Hash{}, false
+	}
+	idx := ref.Number.Uint64() - n - 1
+	if idx >= uint64(len(bc.ancestors)) {
+		return common.Hash{}, false
+	}
+	return bc.ancestors[idx], true
+}
+
+// readAncestorHashes reads the ancestor hashes preimage and checks that it has
+// the expected length and starts at the parent block.
+func (bc *BlockChain) readAncestorHashes() bool {
+	data := oracle.Preimage(bc.ancestorsHash)
+	count := bc.lastBlock.Number.Uint64() + 1
+	if count > 256 {
+		count = 256
+	}
+	if uint64(len(data)) != count*common.HashLength {
+		bc.setError(fmt.Errorf("%w: length %d, want %d", ErrBadAncestorHashes, len(data), count*common.HashLength))
+		return false
+	}
+	ancestors := make([]common.Hash, count)
+	for i := range ancestors {
+		ancestors[i] = common.BytesToHash(data[i*common.HashLength : (i+1)*common.HashLength])
+	}
+	if ancestors[0] != bc.lastBlock.Hash() {
+		bc.setError(fmt.Errorf("%w: first hash %x isn't the parent", ErrBadAncestorHashes, ancestors[0]))
+		return false
+	}
+	bc.ancestors = ancestors
+	return true
+}
+
+// SetTd sets the total difficulty of the parent block, it's the base from which
+// the total difficulty of every ancestor is derived.
+func (bc *BlockChain) SetTd(td *big.Int) {
+	bc.lastTd = td
 }
 
 // GetTd retrieves the total difficulty from the database by hash and number.
+// There is no database, the total difficulty is derived by walking back from the
+// parent block and subtracting the difficulties on the way. It returns nil if
+// the total difficulty of the parent is unknown or hash isn't an ancestor.
 func (bc *BlockChain) GetTd(hash common.Hash, number uint64) *big.Int {
-	log.Fatal("GetTd", hash, number)
-	return nil
+	if bc.lastTd == nil || number > bc.lastBlock.Number.Uint64() {
+		return nil
+	}
+	td := new(big.Int).Set(bc.lastTd)
+	header := bc.lastBlock
+	for header.Number.Uint64() > number {
+		td.Sub(td, header.Difficulty)
+		if header = bc.readParent(header); header == nil {
+			return nil
+		}
+	}
+	if header.Hash() != hash {
+		return nil
+	}
+	return td
 }
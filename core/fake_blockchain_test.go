@@ -0,0 +1,136 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/oracle"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// newTestHeaders returns a chain of n empty headers on top of a genesis
+// header, in order of their number.
+func newTestHeaders(n int) []*types.Header {
+	headers := make([]*types.Header, n+1)
+	for i := range headers {
+		headers[i] = &types.Header{
+			Number:     big.NewInt(int64(i)),
+			Root:       types.EmptyRootHash,
+			Difficulty: big.NewInt(131072),
+			GasLimit:   8000000,
+			UncleHash:  types.EmptyUncleHash,
+		}
+		if i > 0 {
+			headers[i].ParentHash = headers[i-1].Hash()
+		}
+	}
+	return headers
+}
+
+// putHeader puts the RLP of header into the oracle.
+func putHeader(t *testing.T, header *types.Header) {
+	enc, err := rlp.EncodeToBytes(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oracle.PreimageKeyValueWriter{}.Put(crypto.Keccak256(enc), enc)
+}
+
+// TestGetAncestorHash checks that BLOCKHASH reads the ancestor hashes preimage
+// without the headers, and that a list that doesn't fit the parent block fails
+// the chain.
+func TestGetAncestorHash(t *testing.T) {
+	headers := newTestHeaders(300)
+	parent := headers[300]
+	ref := &types.Header{ParentHash: parent.Hash(), Number: big.NewInt(301)}
+
+	var list []byte
+	for n := 300; n > 300-256; n-- {
+		list = append(list, headers[n].Hash().Bytes()...)
+	}
+	tests := []struct {
+		name string
+		list []byte
+		err  error
+	}{
+		{"valid", list, nil},
+		{"bad start", append(common.Hash{}.Bytes(), list[common.HashLength:]...), ErrBadAncestorHashes},
+		{"short", list[:len(list)-common.HashLength], ErrBadAncestorHashes},
+	}
+	for _, test := range tests {
+		// none of the headers is in the oracle, the list must do
+		oracle.UseMemory()
+		hash := crypto.Keccak256Hash(test.list)
+		oracle.PreimageKeyValueWriter{}.Put(hash[:], test.list)
+
+		bc := NewBlockChain(params.AllEthashProtocolChanges, parent)
+		bc.SetAncestorHashes(hash)
+		if test.err != nil {
+			if _, ok := bc.GetAncestorHash(ref, 200); ok {
+				t.Errorf("%s: ancestor hash served", test.name)
+			}
+			if err := bc.Error(); !errors.Is(err, test.err) {
+				t.Errorf("%s: have %v, want %v", test.name, err, test.err)
+			}
+			continue
+		}
+		getHash := GetHashFn(ref, bc)
+		for _, n := range []uint64{300, 299, 200, 45} {
+			if have := getHash(n); have != headers[n].Hash() {
+				t.Errorf("%s: blockhash(%d): have %x, want %x", test.name, n, have, headers[n].Hash())
+			}
+		}
+		if err := bc.Error(); err != nil {
+			t.Errorf("%s: %v", test.name, err)
+		}
+	}
+}
+
+// TestMissingBlockHashHeader checks that a BLOCKHASH the chain can't answer
+// fails the chain instead of reading as zero.
+func TestMissingBlockHashHeader(t *testing.T) {
+	headers := newTestHeaders(10)
+	oracle.UseMemory()
+	for _, header := range headers {
+		// the walk from the parent to block 5 breaks at block 7
+		if header.Number.Uint64() != 7 {
+			putHeader(t, header)
+		}
+	}
+	ref := &types.Header{ParentHash: headers[10].Hash(), Number: big.NewInt(11)}
+	bc := NewBlockChain(params.AllEthashProtocolChanges, headers[10])
+	getHash := GetHashFn(ref, bc)
+	if have := getHash(8); have != headers[8].Hash() {
+		t.Errorf("blockhash(8): have %x, want %x", have, headers[8].Hash())
+	}
+	if err := bc.Error(); err != nil {
+		t.Fatalf("chain failed before the missing header: %v", err)
+	}
+	if have := getHash(5); have != (common.Hash{}) {
+		t.Errorf("blockhash(5): have %x, want zero", have)
+	}
+	if bc.Error() == nil {
+		t.Error("missing header not recorded")
+	}
+}
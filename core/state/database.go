@@ -1,10 +1,13 @@
 package state
 
 import (
+	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/stateless"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethdb"
 	"github.com/ethereum/go-ethereum/oracle"
 	"github.com/ethereum/go-ethereum/trie"
 )
@@ -16,6 +19,7 @@ type Database struct {
 	db          *trie.Database
 	BlockNumber *big.Int
 	StateRoot   common.Hash
+	witness     *stateless.Witness // Records the codes read, nil if not recording
 }
 
 func NewDatabase(header types.Header) Database {
@@ -29,6 +33,12 @@ func NewDatabase(header types.Header) Database {
 func (db *Database) ContractCode(addrHash common.Hash, codeHash common.Hash) ([]byte, error) {
 	oracle.PrefetchCode(db.BlockNumber, addrHash)
 	code := oracle.Preimage(codeHash)
+	if code == nil {
+		return nil, fmt.Errorf("missing code %x", codeHash)
+	}
+	if db.witness != nil {
+		db.witness.AddCode(code)
+	}
 	return code, nil
 }
 
@@ -36,11 +46,25 @@ func (db *Database) ContractCode(addrHash common.Hash, codeHash common.Hash) ([]
 func (db *Database) ContractCodeSize(addrHash common.Hash, codeHash common.Hash) (int, error) {
 	oracle.PrefetchCode(db.BlockNumber, addrHash)
 	code := oracle.Preimage(codeHash)
+	if code == nil {
+		return 0, fmt.Errorf("missing code %x", codeHash)
+	}
+	if db.witness != nil {
+		db.witness.AddCode(code)
+	}
 	return len(code), nil
 }
 
-func (db *Database) CopyTrie(trie Trie) Trie {
-	panic("don't copy tries")
+// CopyTrie returns an independent copy of the given trie. The resolved nodes
+// are shared, they are never modified in place, so either trie copies a node
+// before changing it.
+func (db *Database) CopyTrie(t Trie) Trie {
+	switch t := t.(type) {
+	case *trie.SecureTrie:
+		return t.Copy()
+	default:
+		panic(fmt.Errorf("unknown trie type %T", t))
+	}
 }
 
 // OpenTrie opens the main account trie at a specific root hash.
@@ -89,26 +113,13 @@ type Trie interface {
 	// Commit writes all nodes to the trie's memory database, tracking the internal
 	// and external (for account tries) references.
 	Commit(onleaf trie.LeafCallback) (common.Hash, int, error)
-}
-
-// stubbed: we don't prefetch
-
-type triePrefetcher struct {
-}
-
-func (p *triePrefetcher) prefetch(root common.Hash, keys [][]byte) {
-}
-
-func (p *triePrefetcher) used(root common.Hash, used [][]byte) {
-}
-
-func (p *triePrefetcher) close() {
-}
-
-func (p *triePrefetcher) copy() *triePrefetcher {
-	return p
-}
 
-func (p *triePrefetcher) trie(root common.Hash) Trie {
-	return nil
+	// Prove constructs a Merkle proof for key. The result contains all encoded nodes
+	// on the path to the value at key. The value itself is also included in the last
+	// node and can be retrieved by verifying the proof.
+	//
+	// If the trie does not contain a value for key, the returned proof contains all
+	// nodes of the longest existing prefix of the key (at least the root), ending
+	// with the node that proves the absence of the key.
+	Prove(key []byte, fromLevel uint, proofDb ethdb.KeyValueWriter) error
 }
@@ -0,0 +1,111 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// DiffAccount is the state of an account on one side of a StateDiff.
+type DiffAccount struct {
+	Balance  *hexutil.Big   `json:"balance"`
+	Nonce    hexutil.Uint64 `json:"nonce"`
+	CodeHash common.Hash    `json:"codeHash"`
+}
+
+// DiffSlot is a storage slot that changed, with its value before and after.
+type DiffSlot struct {
+	Before common.Hash `json:"before"`
+	After  common.Hash `json:"after"`
+}
+
+// AccountDiff is the change of a single account. Before is nil for created
+// accounts and After is nil for destroyed ones.
+type AccountDiff struct {
+	Created   bool                     `json:"created,omitempty"`
+	Destroyed bool                     `json:"destroyed,omitempty"`
+	Before    *DiffAccount             `json:"before,omitempty"`
+	After     *DiffAccount             `json:"after,omitempty"`
+	Storage   map[common.Hash]DiffSlot `json:"storage,omitempty"`
+}
+
+// StateDiff is the collection of changed accounts.
+type StateDiff map[common.Address]*AccountDiff
+
+// Diff returns the changes made to the state since parent, which must be a
+// fresh state opened at the original root. It has to be called after
+// Finalise or IntermediateRoot and before Commit, as it walks the dirty state
+// objects. Only the slots read or written are known, so the storage of a
+// destroyed account lists the slots the state transition touched.
+func (s *StateDB) Diff(parent *StateDB) StateDiff {
+	diff := make(StateDiff)
+	for addr := range s.stateObjectsDirty {
+		obj := s.stateObjects[addr]
+		prev := parent.getStateObject(addr)
+		if prev == nil && obj.deleted {
+			// touched, but never existed
+			continue
+		}
+		account := &AccountDiff{
+			Created:   prev == nil,
+			Destroyed: obj.deleted,
+			Storage:   make(map[common.Hash]DiffSlot),
+		}
+		if prev != nil {
+			account.Before = newDiffAccount(prev)
+		}
+		if !obj.deleted {
+			account.After = newDiffAccount(obj)
+		}
+		keys := make(map[common.Hash]struct{})
+		for _, storage := range []Storage{obj.originStorage, obj.pendingStorage, obj.dirtyStorage} {
+			for key := range storage {
+				keys[key] = struct{}{}
+			}
+		}
+		for key := range keys {
+			var slot DiffSlot
+			if prev != nil {
+				slot.Before = prev.GetState(parent.db, key)
+			}
+			if !obj.deleted {
+				slot.After = obj.GetState(s.db, key)
+			}
+			if slot.Before != slot.After {
+				account.Storage[key] = slot
+			}
+		}
+		if !account.Created && !account.Destroyed && len(account.Storage) == 0 && account.Before.equal(account.After) {
+			continue
+		}
+		diff[addr] = account
+	}
+	return diff
+}
+
+func (a *DiffAccount) equal(b *DiffAccount) bool {
+	return a.Balance.ToInt().Cmp(b.Balance.ToInt()) == 0 && a.Nonce == b.Nonce && a.CodeHash == b.CodeHash
+}
+
+func newDiffAccount(obj *stateObject) *DiffAccount {
+	return &DiffAccount{
+		Balance:  (*hexutil.Big)(obj.Balance()),
+		Nonce:    hexutil.Uint64(obj.Nonce()),
+		CodeHash: common.BytesToHash(obj.CodeHash()),
+	}
+}
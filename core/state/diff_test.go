@@ -0,0 +1,106 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestDiff(t *testing.T) {
+	var (
+		storageOnly = common.HexToAddress("0x01")
+		destroyed   = common.HexToAddress("0x02")
+		created     = common.HexToAddress("0x03")
+		touched     = common.HexToAddress("0x04")
+		unchanged   = common.HexToAddress("0x05")
+	)
+	root := newTestState(t, func(s *StateDB) {
+		s.SetBalance(storageOnly, big.NewInt(5))
+		s.SetState(storageOnly, common.HexToHash("0x01"), common.HexToHash("0x01"))
+		s.SetState(storageOnly, common.HexToHash("0x02"), common.HexToHash("0x02"))
+		s.SetBalance(destroyed, big.NewInt(3))
+		s.SetNonce(destroyed, 1)
+		s.SetState(destroyed, common.HexToHash("0x01"), common.HexToHash("0x07"))
+		s.SetBalance(unchanged, big.NewInt(9))
+	})
+	parent, s := openTestState(t, root), openTestState(t, root)
+	s.SetState(storageOnly, common.HexToHash("0x01"), common.HexToHash("0x0a"))
+	// written back to its original value
+	s.SetState(storageOnly, common.HexToHash("0x02"), common.HexToHash("0x0b"))
+	s.SetState(storageOnly, common.HexToHash("0x02"), common.HexToHash("0x02"))
+	s.GetState(destroyed, common.HexToHash("0x01"))
+	s.Suicide(destroyed)
+	s.AddBalance(created, big.NewInt(1))
+	s.AddBalance(touched, new(big.Int))
+	s.AddBalance(unchanged, new(big.Int))
+	s.IntermediateRoot(true)
+
+	have, err := json.MarshalIndent(s.Diff(parent), "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "0x0000000000000000000000000000000000000001": {
+    "before": {
+      "balance": "0x5",
+      "nonce": "0x0",
+      "codeHash": "0xc5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470"
+    },
+    "after": {
+      "balance": "0x5",
+      "nonce": "0x0",
+      "codeHash": "0xc5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470"
+    },
+    "storage": {
+      "0x0000000000000000000000000000000000000000000000000000000000000001": {
+        "before": "0x0000000000000000000000000000000000000000000000000000000000000001",
+        "after": "0x000000000000000000000000000000000000000000000000000000000000000a"
+      }
+    }
+  },
+  "0x0000000000000000000000000000000000000002": {
+    "destroyed": true,
+    "before": {
+      "balance": "0x3",
+      "nonce": "0x1",
+      "codeHash": "0xc5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470"
+    },
+    "storage": {
+      "0x0000000000000000000000000000000000000000000000000000000000000001": {
+        "before": "0x0000000000000000000000000000000000000000000000000000000000000007",
+        "after": "0x0000000000000000000000000000000000000000000000000000000000000000"
+      }
+    }
+  },
+  "0x0000000000000000000000000000000000000003": {
+    "created": true,
+    "after": {
+      "balance": "0x1",
+      "nonce": "0x0",
+      "codeHash": "0xc5d2460186f7233c927e7db2dcc703c0e500b653ca82273b7bfad8045d85a470"
+    }
+  }
+}`
+	if !bytes.Equal(have, []byte(want)) {
+		t.Errorf("diff mismatch\nhave %s\nwant %s", have, want)
+	}
+}
@@ -18,6 +18,7 @@
 package state
 
 import (
+	"errors"
 	"fmt"
 	"math/big"
 	"sort"
@@ -25,6 +26,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/state/snapshot"
+	"github.com/ethereum/go-ethereum/core/stateless"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/log"
@@ -98,6 +100,9 @@ type StateDB struct {
 	// Per-transaction access list
 	accessList *accessList
 
+	// Witness of the state read, nil if not recording
+	witness *stateless.Witness
+
 	// Journal of state modifications. This is the backbone of
 	// Snapshot and RevertToSnapshot.
 	journal        *journal
@@ -155,6 +160,26 @@ func New(root common.Hash, db Database, snaps *snapshot.Tree) (*StateDB, error)
 	return sdb, nil
 }
 
+// StartPrefetcher initializes a new trie prefetcher to pull in nodes from the
+// state trie concurrently while the state is mutated so that when we reach the
+// commit phase, most of the needed data is already hot. There is no prefetcher
+// on MIPS.
+func (s *StateDB) StartPrefetcher() {
+	if s.prefetcher != nil {
+		s.prefetcher.close()
+		s.prefetcher = nil
+	}
+	s.prefetcher = newTriePrefetcher(s.db, s.originalRoot)
+}
+
+// StopPrefetcher terminates a running prefetcher.
+func (s *StateDB) StopPrefetcher() {
+	if s.prefetcher != nil {
+		s.prefetcher.close()
+		s.prefetcher = nil
+	}
+}
+
 // setError remembers the first non-nil error it is called with.
 func (s *StateDB) setError(err error) {
 	if s.dbErr == nil {
@@ -292,7 +317,6 @@ func (s *StateDB) GetState(addr common.Address, hash common.Hash) common.Hash {
 	return common.Hash{}
 }
 
-/*
 // GetProof returns the Merkle proof for a given account.
 func (s *StateDB) GetProof(addr common.Address) ([][]byte, error) {
 	return s.GetProofByHash(crypto.Keccak256Hash(addr.Bytes()))
@@ -305,17 +329,19 @@ func (s *StateDB) GetProofByHash(addrHash common.Hash) ([][]byte, error) {
 	return proof, err
 }
 
-// GetStorageProof returns the Merkle proof for given storage slot.
+// GetStorageProof returns the Merkle proof for given storage slot. The changes
+// not committed yet are written into the storage trie first, as IntermediateRoot
+// does, so they are covered by the proof.
 func (s *StateDB) GetStorageProof(a common.Address, key common.Hash) ([][]byte, error) {
 	var proof proofList
-	trie := s.StorageTrie(a)
-	if trie == nil {
+	stateObject := s.getStateObject(a)
+	if stateObject == nil {
 		return proof, errors.New("storage trie for requested address does not exist")
 	}
-	err := trie.Prove(crypto.Keccak256(key.Bytes()), 0, &proof)
+	stateObject.updateTrie(s.db)
+	err := stateObject.getTrie(s.db).Prove(crypto.Keccak256(key.Bytes()), 0, &proof)
 	return proof, err
 }
-*/
 
 // GetCommittedState retrieves a value from the given account's committed storage trie.
 func (s *StateDB) GetCommittedState(addr common.Address, hash common.Hash) common.Hash {
@@ -326,6 +352,23 @@ func (s *StateDB) GetCommittedState(addr common.Address, hash common.Hash) commo
 	return common.Hash{}
 }
 
+// StartWitness records every trie node, code and key the state reads from
+// now on into w.
+func (s *StateDB) StartWitness(w *stateless.Witness) {
+	s.witness = w
+	s.db.witness = w
+	s.db.db.SetRecorder(w)
+	// the root node was resolved when the state was opened
+	if s.originalRoot != emptyRoot && s.originalRoot != (common.Hash{}) {
+		w.AddState(oracle.Preimage(s.originalRoot))
+	}
+}
+
+// Witness returns the witness the state records into, nil if not recording.
+func (s *StateDB) Witness() *stateless.Witness {
+	return s.witness
+}
+
 // Database retrieves the low level database supporting the lower level trie ops.
 func (s *StateDB) Database() Database {
 	return s.db
@@ -522,6 +565,9 @@ func (s *StateDB) getDeletedStateObject(addr common.Address) *stateObject {
 	if data == nil {
 		start := time.Now()
 		oracle.PrefetchAccount(s.db.BlockNumber, addr, nil)
+		if s.witness != nil {
+			s.witness.AddKey(addr.Bytes())
+		}
 		enc, err := s.trie.TryGet(addr.Bytes())
 		if metrics.EnabledExpensive {
 			s.AccountReads += time.Since(start)
@@ -638,6 +684,7 @@ func (s *StateDB) Copy() *StateDB {
 	// Copy all the basic fields, initialize the memory ones
 	state := &StateDB{
 		db:                  s.db,
+		originalRoot:        s.originalRoot,
 		trie:                s.db.CopyTrie(s.trie),
 		stateObjects:        make(map[common.Address]*stateObject, len(s.journal.dirties)),
 		stateObjectsPending: make(map[common.Address]struct{}, len(s.stateObjectsPending)),
@@ -801,7 +848,7 @@ func (s *StateDB) Finalise(deleteEmptyObjects bool) {
 		addressesToPrefetch = append(addressesToPrefetch, common.CopyBytes(addr[:])) // Copy needed for closure
 	}
 	if s.prefetcher != nil && len(addressesToPrefetch) > 0 {
-		s.prefetcher.prefetch(s.originalRoot, addressesToPrefetch)
+		s.prefetcher.prefetch(common.Address{}, s.originalRoot, addressesToPrefetch)
 	}
 	// Invalidate journal because reverting across transactions is not allowed.
 	s.clearJournalAndRefund()
@@ -842,7 +889,7 @@ func (s *StateDB) IntermediateRoot(deleteEmptyObjects bool) common.Hash {
 	// _untouched_. We can check with the prefetcher, if it can give us a trie
 	// which has the same root, but also has some content loaded into it.
 	if prefetcher != nil {
-		if trie := prefetcher.trie(s.originalRoot); trie != nil {
+		if trie := prefetcher.trie(common.Address{}, s.originalRoot); trie != nil {
 			s.trie = trie
 		}
 	}
@@ -858,7 +905,7 @@ func (s *StateDB) IntermediateRoot(deleteEmptyObjects bool) common.Hash {
 		usedAddrs = append(usedAddrs, common.CopyBytes(addr[:])) // Copy needed for closure
 	}
 	if prefetcher != nil {
-		prefetcher.used(s.originalRoot, usedAddrs)
+		prefetcher.used(common.Address{}, s.originalRoot, usedAddrs)
 	}
 	if len(s.stateObjectsPending) > 0 {
 		s.stateObjectsPending = make(map[common.Address]struct{})
@@ -885,7 +932,8 @@ func (s *StateDB) clearJournalAndRefund() {
 	s.validRevisions = s.validRevisions[:0] // Snapshots can be created without journal entires
 }
 
-// Commit writes the state to the underlying in-memory trie database.
+// Commit writes the state to the underlying in-memory trie database, the
+// committed trie nodes and code are put in the oracle.
 func (s *StateDB) Commit(deleteEmptyObjects bool) (common.Hash, error) {
 	if s.dbErr != nil {
 		return common.Hash{}, fmt.Errorf("commit aborted due to earlier error: %v", s.dbErr)
@@ -895,10 +943,10 @@ func (s *StateDB) Commit(deleteEmptyObjects bool) (common.Hash, error) {
 
 	for addr := range s.stateObjectsDirty {
 		if obj := s.stateObjects[addr]; !obj.deleted {
-			fmt.Println("dirty state object", addr)
 			// Write any contract code associated with the state object
 			if obj.code != nil && obj.dirtyCode {
-				fmt.Println("write code", common.BytesToHash(obj.CodeHash()))
+				oracle.PreimageKeyValueWriter{}.Put(obj.CodeHash(), obj.code)
+				obj.dirtyCode = false
 			}
 			// Write any storage changes in the state object to its storage trie
 			if _, err := obj.CommitTrie(s.db); err != nil {
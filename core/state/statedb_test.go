@@ -0,0 +1,186 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package state
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/oracle"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// newTestState commits the state setup builds into an emptied oracle and
+// returns its root.
+func newTestState(t *testing.T, setup func(s *StateDB)) common.Hash {
+	oracle.UseMemory()
+	s := openTestState(t, types.EmptyRootHash)
+	setup(s)
+	root, err := s.Commit(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+// openTestState opens the state at root from the oracle.
+func openTestState(t *testing.T, root common.Hash) *StateDB {
+	s, err := New(root, NewDatabase(types.Header{Number: new(big.Int), Root: root}), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+// TestCommitReopen checks that a committed state, its storage and code can be
+// opened again from the root alone.
+func TestCommitReopen(t *testing.T) {
+	var (
+		addr = common.HexToAddress("0xaaaa")
+		slot = common.HexToHash("0x01")
+		code = []byte{0x60, 0x2a, 0x60, 0x00, 0x55}
+	)
+	root := newTestState(t, func(s *StateDB) {
+		s.SetBalance(addr, big.NewInt(100))
+		s.SetState(addr, slot, common.HexToHash("0x11"))
+		s.SetCode(addr, code)
+	})
+	s := openTestState(t, root)
+	if have := s.GetBalance(addr); have.Cmp(big.NewInt(100)) != 0 {
+		t.Errorf("balance: have %v, want 100", have)
+	}
+	if have := s.GetState(addr, slot); have != common.HexToHash("0x11") {
+		t.Errorf("slot: have %x, want 0x11", have)
+	}
+	if have := s.GetCode(addr); !bytes.Equal(have, code) {
+		t.Errorf("code: have %x, want %x", have, code)
+	}
+	if err := s.Error(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestStateCopy checks that a copy of a state with resolved tries and pending
+// changes diverges independently from the original.
+func TestStateCopy(t *testing.T) {
+	var (
+		addr  = common.HexToAddress("0xaaaa")
+		other = common.HexToAddress("0xbbbb")
+		slot  = common.HexToHash("0x01")
+		slot2 = common.HexToHash("0x02")
+	)
+	root := newTestState(t, func(s *StateDB) {
+		s.SetBalance(addr, big.NewInt(100))
+		s.SetState(addr, slot, common.HexToHash("0x11"))
+		s.SetState(addr, slot2, common.HexToHash("0x22"))
+		s.SetBalance(other, big.NewInt(200))
+	})
+	newState := func() *StateDB { return openTestState(t, root) }
+
+	orig := newState()
+	orig.SetState(addr, slot, common.HexToHash("0x33"))
+	orig.AddBalance(other, big.NewInt(1))
+	orig.Finalise(true)
+	orig.IntermediateRoot(true)
+
+	cpy := orig.Copy()
+	orig.SetState(addr, slot2, common.HexToHash("0x44"))
+	orig.SubBalance(addr, big.NewInt(10))
+	cpy.SetState(addr, slot, common.HexToHash("0x55"))
+	cpy.SetNonce(other, 7)
+
+	if got := orig.GetState(addr, slot); got != common.HexToHash("0x33") {
+		t.Errorf("original slot 1: have %x, want 0x33", got)
+	}
+	if got := cpy.GetState(addr, slot2); got != common.HexToHash("0x22") {
+		t.Errorf("copied slot 2: have %x, want 0x22", got)
+	}
+	if got := cpy.GetBalance(addr); got.Cmp(big.NewInt(100)) != 0 {
+		t.Errorf("copied balance: have %v, want 100", got)
+	}
+	if got := orig.GetNonce(other); got != 0 {
+		t.Errorf("original nonce: have %d, want 0", got)
+	}
+
+	// Both roots have to match a state making the same changes from scratch
+	want := newState()
+	want.SetState(addr, slot, common.HexToHash("0x33"))
+	want.SetState(addr, slot2, common.HexToHash("0x44"))
+	want.AddBalance(other, big.NewInt(1))
+	want.SubBalance(addr, big.NewInt(10))
+	if have, want := orig.IntermediateRoot(true), want.IntermediateRoot(true); have != want {
+		t.Errorf("original root: have %x, want %x", have, want)
+	}
+	want = newState()
+	want.SetState(addr, slot, common.HexToHash("0x55"))
+	want.AddBalance(other, big.NewInt(1))
+	want.SetNonce(other, 7)
+	if have, want := cpy.IntermediateRoot(true), want.IntermediateRoot(true); have != want {
+		t.Errorf("copied root: have %x, want %x", have, want)
+	}
+}
+
+// proofReader serves the nodes of a proof by their hash.
+type proofReader map[common.Hash][]byte
+
+func (p proofReader) Has(key []byte) (bool, error) {
+	_, ok := p[common.BytesToHash(key)]
+	return ok, nil
+}
+
+func (p proofReader) Get(key []byte) ([]byte, error) {
+	if node, ok := p[common.BytesToHash(key)]; ok {
+		return node, nil
+	}
+	return nil, errors.New("not found")
+}
+
+// TestStorageProofPending checks that a storage proof covers the changes not
+// committed yet.
+func TestStorageProofPending(t *testing.T) {
+	var (
+		addr = common.HexToAddress("0xaaaa")
+		slot = common.HexToHash("0x01")
+	)
+	root := newTestState(t, func(s *StateDB) {
+		s.SetState(addr, slot, common.HexToHash("0x11"))
+		s.SetState(addr, common.HexToHash("0x02"), common.HexToHash("0x12"))
+	})
+	s := openTestState(t, root)
+	s.SetState(addr, slot, common.HexToHash("0x22"))
+
+	proof, err := s.GetStorageProof(addr, slot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nodes := make(proofReader)
+	for _, node := range proof {
+		nodes[crypto.Keccak256Hash(node)] = node
+	}
+	val, err := trie.VerifyProof(s.getStateObject(addr).getTrie(s.db).Hash(), crypto.Keccak256(slot[:]), nodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []byte{0x22}; !bytes.Equal(val, want) {
+		t.Errorf("value: have %x, want %x", val, want)
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2020 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build mips
+// +build mips
+
+package state
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// triePrefetcher is never created on MIPS, the oracle serves every node
+// without waiting and there is nothing to load in the background.
+type triePrefetcher struct{}
+
+func newTriePrefetcher(db Database, root common.Hash) *triePrefetcher {
+	return nil
+}
+
+func (p *triePrefetcher) prefetch(owner common.Address, root common.Hash, keys [][]byte) {
+}
+
+func (p *triePrefetcher) used(owner common.Address, root common.Hash, used [][]byte) {
+}
+
+func (p *triePrefetcher) close() {
+}
+
+func (p *triePrefetcher) copy() *triePrefetcher {
+	return p
+}
+
+func (p *triePrefetcher) trie(owner common.Address, root common.Hash) Trie {
+	return nil
+}
@@ -0,0 +1,153 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+//go:build !mips
+// +build !mips
+
+package state
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/oracle"
+)
+
+var prefetchAddr = common.HexToAddress("0xaffeaffeaffeaffeaffeaffeaffeaffeaffeaffe")
+
+// filledStateDB commits an account with a filled storage trie and returns the
+// state opened at its root.
+func filledStateDB(t *testing.T) *StateDB {
+	root := newTestState(t, func(s *StateDB) {
+		s.SetBalance(prefetchAddr, big.NewInt(42))                                 // Change the account trie
+		s.SetCode(prefetchAddr, []byte("hello"))                                   // Change an external metadata
+		s.SetState(prefetchAddr, common.HexToHash("aaa"), common.HexToHash("bbb")) // Change the storage trie
+		for i := 0; i < 100; i++ {
+			sk := common.BigToHash(big.NewInt(int64(i)))
+			s.SetState(prefetchAddr, sk, sk) // Change the storage trie
+		}
+	})
+	return openTestState(t, root)
+}
+
+func TestCopyAndClose(t *testing.T) {
+	db := filledStateDB(t)
+	prefetcher := newTriePrefetcher(db.db, db.originalRoot)
+	skey := common.HexToHash("aaa")
+	prefetcher.prefetch(common.Address{}, db.originalRoot, [][]byte{skey.Bytes()})
+	prefetcher.prefetch(common.Address{}, db.originalRoot, [][]byte{skey.Bytes()})
+	a := prefetcher.trie(common.Address{}, db.originalRoot)
+	prefetcher.prefetch(common.Address{}, db.originalRoot, [][]byte{skey.Bytes()})
+	b := prefetcher.trie(common.Address{}, db.originalRoot)
+	cpy := prefetcher.copy()
+	cpy.prefetch(common.Address{}, db.originalRoot, [][]byte{skey.Bytes()})
+	cpy.prefetch(common.Address{}, db.originalRoot, [][]byte{skey.Bytes()})
+	c := cpy.trie(common.Address{}, db.originalRoot)
+	prefetcher.close()
+	cpy2 := cpy.copy()
+	cpy2.prefetch(common.Address{}, db.originalRoot, [][]byte{skey.Bytes()})
+	d := cpy2.trie(common.Address{}, db.originalRoot)
+	cpy.close()
+	cpy2.close()
+	if a.Hash() != b.Hash() || a.Hash() != c.Hash() || a.Hash() != d.Hash() {
+		t.Fatalf("Invalid trie, hashes should be equal: %v %v %v %v", a.Hash(), b.Hash(), c.Hash(), d.Hash())
+	}
+}
+
+func TestUseAfterClose(t *testing.T) {
+	db := filledStateDB(t)
+	prefetcher := newTriePrefetcher(db.db, db.originalRoot)
+	skey := common.HexToHash("aaa")
+	prefetcher.prefetch(common.Address{}, db.originalRoot, [][]byte{skey.Bytes()})
+	a := prefetcher.trie(common.Address{}, db.originalRoot)
+	prefetcher.close()
+	b := prefetcher.trie(common.Address{}, db.originalRoot)
+	if a == nil {
+		t.Fatal("Prefetching before close should not return nil")
+	}
+	if b != nil {
+		t.Fatal("Trie after close should return nil")
+	}
+}
+
+func TestCopyClose(t *testing.T) {
+	db := filledStateDB(t)
+	prefetcher := newTriePrefetcher(db.db, db.originalRoot)
+	skey := common.HexToHash("aaa")
+	prefetcher.prefetch(common.Address{}, db.originalRoot, [][]byte{skey.Bytes()})
+	cpy := prefetcher.copy()
+	a := prefetcher.trie(common.Address{}, db.originalRoot)
+	b := cpy.trie(common.Address{}, db.originalRoot)
+	prefetcher.close()
+	cpy2 := cpy.copy()
+	c := prefetcher.trie(common.Address{}, db.originalRoot)
+	d := cpy.trie(common.Address{}, db.originalRoot)
+	e := cpy2.trie(common.Address{}, db.originalRoot)
+	if a == nil {
+		t.Fatal("Prefetching before close should not return nil")
+	}
+	if b == nil {
+		t.Fatal("Copy trie should return nil")
+	}
+	if c != nil {
+		t.Fatal("Trie after close should return nil")
+	}
+	if d == nil {
+		t.Fatal("Copy trie should not return nil")
+	}
+	if e == nil {
+		t.Fatal("Copy trie should not return nil")
+	}
+}
+
+// TestPrefetchedRoot changes the storage while the prefetcher loads the tries
+// in the background, the root must match the one of a state without it. Run
+// with -race, the subfetchers read the oracle concurrently.
+func TestPrefetchedRoot(t *testing.T) {
+	db := filledStateDB(t)
+	change := func(s *StateDB) common.Hash {
+		for i := 0; i < 100; i += 3 {
+			s.SetState(prefetchAddr, common.BigToHash(big.NewInt(int64(i))), common.Hash{})
+			// another transaction
+			s.Finalise(true)
+		}
+		s.AddBalance(common.HexToAddress("0x01"), big.NewInt(1))
+		return s.IntermediateRoot(true)
+	}
+	want := change(openTestState(t, db.originalRoot))
+
+	db.StartPrefetcher()
+	defer db.StopPrefetcher()
+	if have := change(db); have != want {
+		t.Errorf("root: have %x, want %x", have, want)
+	}
+}
+
+// TestUseMemoryWhilePrefetching switches the oracle over, as the next state
+// does, while the subfetchers still read it. Run with -race.
+func TestUseMemoryWhilePrefetching(t *testing.T) {
+	db := filledStateDB(t)
+	prefetcher := newTriePrefetcher(db.db, db.originalRoot)
+	defer prefetcher.close()
+
+	var keys [][]byte
+	for i := 0; i < 100; i++ {
+		keys = append(keys, common.BigToAddress(big.NewInt(int64(i))).Bytes())
+	}
+	prefetcher.prefetch(common.Address{}, db.originalRoot, keys)
+	oracle.UseMemory()
+}
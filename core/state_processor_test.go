@@ -0,0 +1,82 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/oracle"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// TestProcessDAOFork checks that the DAO fork block drains the DAO accounts
+// into the refund contract on the supporting side only.
+func TestProcessDAOFork(t *testing.T) {
+	drained := params.DAODrainList()[0]
+	for _, support := range []bool{true, false} {
+		config := &params.ChainConfig{
+			ChainID:        big.NewInt(1),
+			HomesteadBlock: big.NewInt(0),
+			DAOForkBlock:   big.NewInt(11),
+			DAOForkSupport: support,
+			Ethash:         new(params.EthashConfig),
+		}
+		oracle.UseMemory()
+		parent := &types.Header{
+			Number:     big.NewInt(10),
+			Root:       types.EmptyRootHash,
+			Difficulty: big.NewInt(131072),
+			GasLimit:   8000000,
+			UncleHash:  types.EmptyUncleHash,
+		}
+		statedb, err := state.New(parent.Root, state.NewDatabase(*parent), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		statedb.AddBalance(drained, big.NewInt(5))
+
+		header := &types.Header{
+			ParentHash: parent.Hash(),
+			UncleHash:  types.EmptyUncleHash,
+			Coinbase:   common.HexToAddress("0xc014ba5e"),
+			Number:     big.NewInt(11),
+			GasLimit:   parent.GasLimit,
+			Difficulty: parent.Difficulty,
+		}
+		bc := NewBlockChain(config, parent)
+		processor := NewStateProcessor(config, bc, ethash.NewFaker())
+		if _, _, _, err := processor.Process(types.NewBlockWithHeader(header), statedb, vm.Config{}); err != nil {
+			t.Fatalf("support %v: %v", support, err)
+		}
+		want := new(big.Int)
+		if support {
+			want.SetInt64(5)
+		}
+		if have := statedb.GetBalance(params.DAORefundContract); have.Cmp(want) != 0 {
+			t.Errorf("support %v: refund contract balance: have %v, want %v", support, have, want)
+		}
+		if have := statedb.GetBalance(drained); have.Cmp(new(big.Int).Sub(big.NewInt(5), want)) != 0 {
+			t.Errorf("support %v: drained account balance: have %v", support, have)
+		}
+	}
+}
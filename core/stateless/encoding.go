@@ -0,0 +1,193 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package stateless
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// extWitness is a witness JSON encoding for transferring across clients. It
+// has the shape of the debug_executionWitness result, with the headers RLP
+// encoded, newest first. The other lists are sorted to make the encoding
+// deterministic.
+type extWitness struct {
+	Root    common.Hash     `json:"root"`
+	Headers []hexutil.Bytes `json:"headers"`
+	Uncles  []hexutil.Bytes `json:"uncles,omitempty"`
+	Codes   []hexutil.Bytes `json:"codes"`
+	State   []hexutil.Bytes `json:"state"`
+	Keys    []hexutil.Bytes `json:"keys"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (w *Witness) MarshalJSON() ([]byte, error) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	headers := make([]*types.Header, 0, len(w.Headers))
+	for _, header := range w.Headers {
+		headers = append(headers, header)
+	}
+	sort.Slice(headers, func(i, j int) bool {
+		return headers[i].Number.Cmp(headers[j].Number) > 0
+	})
+	ext := &extWitness{
+		Root:    w.Root,
+		Headers: make([]hexutil.Bytes, len(headers)),
+		Uncles:  sortedBytes(w.Uncles),
+		Codes:   sortedBytes(w.Codes),
+		State:   sortedBytes(w.State),
+		Keys:    sortedBytes(w.Keys),
+	}
+	for i, header := range headers {
+		enc, err := rlp.EncodeToBytes(header)
+		if err != nil {
+			return nil, err
+		}
+		ext.Headers[i] = enc
+	}
+	return json.Marshal(ext)
+}
+
+// UnmarshalJSON implements json.Unmarshaler. Besides the encoding of
+// MarshalJSON, it accepts the debug_executionWitness result of other clients,
+// which has the headers as JSON objects and no root. The root is taken from
+// the newest header then, the parent of the block.
+func (w *Witness) UnmarshalJSON(input []byte) error {
+	var ext struct {
+		Root    common.Hash       `json:"root"`
+		Headers []json.RawMessage `json:"headers"`
+		Uncles  []hexutil.Bytes   `json:"uncles"`
+		Codes   []hexutil.Bytes   `json:"codes"`
+		State   []hexutil.Bytes   `json:"state"`
+		Keys    []hexutil.Bytes   `json:"keys"`
+	}
+	if err := json.Unmarshal(input, &ext); err != nil {
+		return err
+	}
+	var parent *types.Header
+	w.Headers = make(map[common.Hash]*types.Header, len(ext.Headers))
+	for _, raw := range ext.Headers {
+		header, err := decodeHeader(raw)
+		if err != nil {
+			return err
+		}
+		if parent == nil || header.Number.Cmp(parent.Number) > 0 {
+			parent = header
+		}
+		w.Headers[header.Hash()] = header
+	}
+	w.Root = ext.Root
+	if w.Root == (common.Hash{}) && parent != nil {
+		w.Root = parent.Root
+	}
+	w.Uncles = bytesSet(ext.Uncles)
+	w.Codes = bytesSet(ext.Codes)
+	w.State = bytesSet(ext.State)
+	w.Keys = bytesSet(ext.Keys)
+	return nil
+}
+
+// jsonHeader is a header as returned by the RPC API.
+type jsonHeader struct {
+	ParentHash  common.Hash      `json:"parentHash"`
+	UncleHash   common.Hash      `json:"sha3Uncles"`
+	Coinbase    common.Address   `json:"miner"`
+	Root        common.Hash      `json:"stateRoot"`
+	TxHash      common.Hash      `json:"transactionsRoot"`
+	ReceiptHash common.Hash      `json:"receiptsRoot"`
+	Bloom       types.Bloom      `json:"logsBloom"`
+	Difficulty  *hexutil.Big     `json:"difficulty"`
+	Number      *hexutil.Big     `json:"number"`
+	GasLimit    hexutil.Uint64   `json:"gasLimit"`
+	GasUsed     hexutil.Uint64   `json:"gasUsed"`
+	Time        hexutil.Uint64   `json:"timestamp"`
+	Extra       hexutil.Bytes    `json:"extraData"`
+	MixDigest   common.Hash      `json:"mixHash"`
+	Nonce       types.BlockNonce `json:"nonce"`
+	BaseFee     *hexutil.Big     `json:"baseFeePerGas"`
+}
+
+// decodeHeader decodes a header given either as RLP in a hex string or as a
+// JSON object.
+func decodeHeader(raw json.RawMessage) (*types.Header, error) {
+	header := new(types.Header)
+	var enc hexutil.Bytes
+	if err := json.Unmarshal(raw, &enc); err == nil {
+		if err := rlp.DecodeBytes(enc, header); err != nil {
+			return nil, err
+		}
+		return header, nil
+	}
+	var dec jsonHeader
+	if err := json.Unmarshal(raw, &dec); err != nil {
+		return nil, err
+	}
+	if dec.Number == nil || dec.Difficulty == nil {
+		return nil, errors.New("header without number or difficulty")
+	}
+	header.ParentHash = dec.ParentHash
+	header.UncleHash = dec.UncleHash
+	header.Coinbase = dec.Coinbase
+	header.Root = dec.Root
+	header.TxHash = dec.TxHash
+	header.ReceiptHash = dec.ReceiptHash
+	header.Bloom = dec.Bloom
+	header.Difficulty = (*big.Int)(dec.Difficulty)
+	header.Number = (*big.Int)(dec.Number)
+	header.GasLimit = uint64(dec.GasLimit)
+	header.GasUsed = uint64(dec.GasUsed)
+	header.Time = uint64(dec.Time)
+	header.Extra = dec.Extra
+	header.MixDigest = dec.MixDigest
+	header.Nonce = dec.Nonce
+	if dec.BaseFee != nil {
+		header.BaseFee = (*big.Int)(dec.BaseFee)
+	}
+	return header, nil
+}
+
+// sortedBytes returns the members of a set of blobs in ascending order.
+func sortedBytes(set map[string]struct{}) []hexutil.Bytes {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	list := make([]hexutil.Bytes, len(keys))
+	for i, key := range keys {
+		list[i] = []byte(key)
+	}
+	return list
+}
+
+// bytesSet returns a set of the given blobs.
+func bytesSet(list []hexutil.Bytes) map[string]struct{} {
+	set := make(map[string]struct{}, len(list))
+	for _, blob := range list {
+		set[string(blob)] = struct{}{}
+	}
+	return set
+}
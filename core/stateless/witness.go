@@ -0,0 +1,99 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package stateless collects the witness of a block: the parts of the chain
+// and the state its execution reads, enough to execute it again without a
+// node.
+package stateless
+
+import (
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Witness encompasses the state required to apply a set of transactions and
+// derive a post state/receipt root.
+type Witness struct {
+	Root    common.Hash                   // State root of the parent block
+	Headers map[common.Hash]*types.Header // Parent and the ancestors read for BLOCKHASH or uncle checks
+	Uncles  map[string]struct{}           // Uncle lists of the ancestors read for uncle checks
+	Codes   map[string]struct{}           // Set of bytecodes ran or accessed
+	State   map[string]struct{}           // Set of MPT state trie nodes (account and storage together)
+	Keys    map[string]struct{}           // Accounts and storage slots read from the tries
+
+	lock sync.Mutex // Lock to allow concurrent state insertions
+}
+
+// NewWitness creates an empty witness ready for population, starting at the
+// parent of the block to execute.
+func NewWitness(parent *types.Header) *Witness {
+	w := &Witness{
+		Root:    parent.Root,
+		Headers: make(map[common.Hash]*types.Header),
+		Uncles:  make(map[string]struct{}),
+		Codes:   make(map[string]struct{}),
+		State:   make(map[string]struct{}),
+		Keys:    make(map[string]struct{}),
+	}
+	w.AddHeader(parent)
+	return w
+}
+
+// AddHeader adds a header read from the chain to the witness.
+func (w *Witness) AddHeader(header *types.Header) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.Headers[header.Hash()] = header
+}
+
+// AddUncles adds the encoded uncle list of an ancestor to the witness.
+func (w *Witness) AddUncles(uncles []byte) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.Uncles[string(uncles)] = struct{}{}
+}
+
+// AddCode adds a bytecode blob to the witness.
+func (w *Witness) AddCode(code []byte) {
+	if len(code) == 0 {
+		return
+	}
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.Codes[string(code)] = struct{}{}
+}
+
+// AddState adds a trie node to the witness.
+func (w *Witness) AddState(node []byte) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.State[string(node)] = struct{}{}
+}
+
+// AddKey adds the address of an account or the key of a storage slot read
+// from the tries to the witness.
+func (w *Witness) AddKey(key []byte) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	w.Keys[string(key)] = struct{}{}
+}
@@ -17,10 +17,17 @@
 package vm
 
 import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
 	"crypto/sha256"
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"math/big"
+	"sort"
+	"strings"
+	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/math"
@@ -93,7 +100,7 @@ var PrecompiledContractsBerlin = map[common.Address]PrecompiledContract{
 }
 
 // PrecompiledContractsBLS contains the set of pre-compiled Ethereum
-// contracts specified in EIP-2537. These are exported for testing purposes.
+// contracts specified in EIP-2537. Chain configs enable them as "eip2537".
 var PrecompiledContractsBLS = map[common.Address]PrecompiledContract{
 	common.BytesToAddress([]byte{10}): &bls12381G1Add{},
 	common.BytesToAddress([]byte{11}): &bls12381G1Mul{},
@@ -128,18 +135,115 @@ func init() {
 	}
 }
 
-// ActivePrecompiles returns the precompiles enabled with the current configuration.
-func ActivePrecompiles(rules params.Rules) []common.Address {
+// PrecompiledContractsP256 contains the secp256r1 signature verification
+// contract specified in RIP-7212. Chain configs enable it as "rip7212".
+var PrecompiledContractsP256 = map[common.Address]PrecompiledContract{
+	common.BytesToAddress([]byte{0x01, 0x00}): &p256Verify{},
+}
+
+// PrecompileSets contains the named sets of pre-compiled contracts that a chain
+// configuration can enable on top of the fork defaults, see
+// params.PrecompileUpgrade.
+var PrecompileSets = map[string]map[common.Address]PrecompiledContract{
+	"eip2537": PrecompiledContractsBLS,
+	"rip7212": PrecompiledContractsP256,
+}
+
+// RegisterPrecompileSet makes a named set of chain specific pre-compiled
+// contracts available to chain configurations. It panics if the name is
+// already taken.
+func RegisterPrecompileSet(name string, contracts map[common.Address]PrecompiledContract) {
+	if _, exist := PrecompileSets[name]; exist {
+		panic(fmt.Sprintf("precompile set %q already registered", name))
+	}
+	PrecompileSets[name] = contracts
+}
+
+// CheckPrecompileUpgrades checks that the precompile upgrades of a chain config
+// only enable sets in PrecompileSets.
+func CheckPrecompileUpgrades(config *params.ChainConfig) error {
+	for i, u := range config.PrecompileUpgrades {
+		for _, name := range u.Enable {
+			if _, ok := PrecompileSets[name]; !ok {
+				return fmt.Errorf("precompile upgrade %d enables unknown set %q", i, name)
+			}
+		}
+	}
+	return nil
+}
+
+// precompiles holds the pre-compiled contracts enabled under some rules and
+// their addresses.
+type precompiles struct {
+	contracts map[common.Address]PrecompiledContract
+	addresses []common.Address
+}
+
+var (
+	// mergedPrecompiles caches the fork defaults extended by the sets a chain
+	// config enables, keyed by the fork and the names of the sets.
+	mergedPrecompiles     = make(map[string]precompiles)
+	mergedPrecompilesLock sync.Mutex
+)
+
+// activePrecompiles returns the pre-compiled contracts enabled under the given
+// rules: the fork defaults extended by the sets the chain config enables.
+// Later sets take precedence over earlier ones on the same address. The set
+// names must have passed CheckPrecompileUpgrades.
+func activePrecompiles(rules params.Rules) precompiles {
+	var (
+		fork     string
+		defaults precompiles
+	)
 	switch {
 	case rules.IsBerlin:
-		return PrecompiledAddressesBerlin
+		fork, defaults = "berlin", precompiles{PrecompiledContractsBerlin, PrecompiledAddressesBerlin}
 	case rules.IsIstanbul:
-		return PrecompiledAddressesIstanbul
+		fork, defaults = "istanbul", precompiles{PrecompiledContractsIstanbul, PrecompiledAddressesIstanbul}
 	case rules.IsByzantium:
-		return PrecompiledAddressesByzantium
+		fork, defaults = "byzantium", precompiles{PrecompiledContractsByzantium, PrecompiledAddressesByzantium}
 	default:
-		return PrecompiledAddressesHomestead
+		fork, defaults = "homestead", precompiles{PrecompiledContractsHomestead, PrecompiledAddressesHomestead}
+	}
+	if len(rules.Precompiles) == 0 {
+		return defaults
+	}
+	key := fork + "," + strings.Join(rules.Precompiles, ",")
+
+	mergedPrecompilesLock.Lock()
+	defer mergedPrecompilesLock.Unlock()
+
+	if merged, ok := mergedPrecompiles[key]; ok {
+		return merged
+	}
+	contracts := make(map[common.Address]PrecompiledContract, len(defaults.contracts))
+	for addr, p := range defaults.contracts {
+		contracts[addr] = p
 	}
+	for _, name := range rules.Precompiles {
+		set, ok := PrecompileSets[name]
+		if !ok {
+			panic(fmt.Sprintf("unknown precompile set %q", name))
+		}
+		for addr, p := range set {
+			contracts[addr] = p
+		}
+	}
+	addrs := make([]common.Address, 0, len(contracts))
+	for addr := range contracts {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		return bytes.Compare(addrs[i][:], addrs[j][:]) < 0
+	})
+	merged := precompiles{contracts, addrs}
+	mergedPrecompiles[key] = merged
+	return merged
+}
+
+// ActivePrecompiles returns the precompiles enabled with the current configuration.
+func ActivePrecompiles(rules params.Rules) []common.Address {
+	return activePrecompiles(rules).addresses
 }
 
 // RunPrecompiledContract runs and evaluates the output of a precompiled contract.
@@ -1043,3 +1147,44 @@ func (c *bls12381MapG2) Run(input []byte) ([]byte, error) {
 	// Encode the G2 point to 256 bytes
 	return g.EncodePoint(r), nil
 }
+
+// p256Verify implements the secp256r1 signature verification precompile
+// specified in RIP-7212.
+type p256Verify struct{}
+
+// RequiredGas returns the gas required to execute the pre-compiled contract.
+func (c *p256Verify) RequiredGas(input []byte) uint64 {
+	return params.P256VerifyGas
+}
+
+// Run verifies the signature in input, which holds the message hash, the r and
+// s components of the signature and the x and y coordinates of the public key,
+// 32 bytes each. A valid signature returns 1 as a 32 byte word. An invalid
+// signature or an input of another length returns no data and no error, the
+// gas is charged either way.
+func (c *p256Verify) Run(input []byte) ([]byte, error) {
+	const p256VerifyInputLength = 160
+	if len(input) != p256VerifyInputLength {
+		return nil, nil
+	}
+	var (
+		hash = input[:32]
+		r    = new(big.Int).SetBytes(input[32:64])
+		s    = new(big.Int).SetBytes(input[64:96])
+		x    = new(big.Int).SetBytes(input[96:128])
+		y    = new(big.Int).SetBytes(input[128:160])
+	)
+	curve := elliptic.P256()
+	n := curve.Params().N
+	if r.Sign() == 0 || s.Sign() == 0 || r.Cmp(n) >= 0 || s.Cmp(n) >= 0 {
+		return nil, nil
+	}
+	// The point at infinity and points off the curve are rejected
+	if (x.Sign() == 0 && y.Sign() == 0) || !curve.IsOnCurve(x, y) {
+		return nil, nil
+	}
+	if !ecdsa.Verify(&ecdsa.PublicKey{Curve: curve, X: x, Y: y}, hash, r, s) {
+		return nil, nil
+	}
+	return common.LeftPadBytes(common.Big1.Bytes(), 32), nil
+}
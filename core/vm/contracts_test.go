@@ -0,0 +1,235 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package vm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/oracle"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// precompiledTest defines the input/output pairs for precompiled contract tests.
+type precompiledTest struct {
+	Input, Expected string
+	Gas             uint64
+	Name            string
+	NoBenchmark     bool // Benchmark primarily the worst-cases
+}
+
+// allPrecompiles holds the precompiles under test by address, including the
+// ones chain configs enable on top of the fork defaults.
+var allPrecompiles = map[common.Address]PrecompiledContract{
+	common.BytesToAddress([]byte{0x01, 0x00}): &p256Verify{},
+}
+
+func testPrecompiled(addr string, test precompiledTest, t *testing.T) {
+	p := allPrecompiles[common.HexToAddress(addr)]
+	in := common.Hex2Bytes(test.Input)
+	gas := p.RequiredGas(in)
+	t.Run(fmt.Sprintf("%s-Gas=%d", test.Name, gas), func(t *testing.T) {
+		if res, _, err := RunPrecompiledContract(p, in, gas); err != nil {
+			t.Error(err)
+		} else if common.Bytes2Hex(res) != test.Expected {
+			t.Errorf("Expected %v, got %v", test.Expected, common.Bytes2Hex(res))
+		}
+		if expGas := test.Gas; expGas != gas {
+			t.Errorf("%v: gas wrong, expected %d, got %d", test.Name, expGas, gas)
+		}
+		// Verify that the precompile did not touch the input buffer
+		exp := common.Hex2Bytes(test.Input)
+		if !bytes.Equal(in, exp) {
+			t.Errorf("Precompiled %v modified input data", addr)
+		}
+	})
+}
+
+func testPrecompiledOOG(addr string, test precompiledTest, t *testing.T) {
+	p := allPrecompiles[common.HexToAddress(addr)]
+	in := common.Hex2Bytes(test.Input)
+	gas := p.RequiredGas(in) - 1
+
+	t.Run(fmt.Sprintf("%s-Gas=%d", test.Name, gas), func(t *testing.T) {
+		_, _, err := RunPrecompiledContract(p, in, gas)
+		if err != ErrOutOfGas {
+			t.Errorf("Expected error [out of gas], got [%v]", err)
+		}
+	})
+}
+
+// Tests the sample inputs of the secp256r1 signature verification of RIP-7212.
+func TestPrecompiledP256Verify(t *testing.T) { testJson("p256Verify", "0100", t) }
+
+// Tests that the secp256r1 signature verification fails without enough gas.
+func TestPrecompiledP256VerifyOOG(t *testing.T) {
+	tests, err := loadJson("p256Verify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	testPrecompiledOOG("0100", tests[0], t)
+}
+
+func testJson(name, addr string, t *testing.T) {
+	tests, err := loadJson(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range tests {
+		testPrecompiled(addr, test, t)
+	}
+}
+
+func loadJson(name string) ([]precompiledTest, error) {
+	data, err := ioutil.ReadFile(fmt.Sprintf("testdata/precompiles/%v.json", name))
+	if err != nil {
+		return nil, err
+	}
+	var testcases []precompiledTest
+	err = json.Unmarshal(data, &testcases)
+	return testcases, err
+}
+
+// Tests that the precompiles of a chain enabling extra sets are reported in
+// address order.
+func TestActivePrecompilesSorted(t *testing.T) {
+	config := *params.TestChainConfig
+	config.PrecompileUpgrades = []params.PrecompileUpgrade{
+		{Block: big.NewInt(0), Enable: []string{"rip7212", "eip2537"}},
+	}
+	for i := 0; i < 10; i++ {
+		addrs := ActivePrecompiles(config.Rules(new(big.Int), false))
+		if want := len(PrecompiledContractsBerlin) + len(PrecompiledContractsBLS) + len(PrecompiledContractsP256); len(addrs) != want {
+			t.Fatalf("have %d precompiles, want %d", len(addrs), want)
+		}
+		for j := 1; j < len(addrs); j++ {
+			if bytes.Compare(addrs[j-1][:], addrs[j][:]) >= 0 {
+				t.Fatalf("precompiles out of order: %x before %x", addrs[j-1], addrs[j])
+			}
+		}
+	}
+}
+
+// Tests that a chain config enabling a set core/vm doesn't provide is rejected
+// instead of making NewEVM panic.
+func TestCheckPrecompileUpgrades(t *testing.T) {
+	config := *params.TestChainConfig
+	config.PrecompileUpgrades = []params.PrecompileUpgrade{
+		{Block: big.NewInt(5), Enable: []string{"rip7212"}},
+	}
+	if err := CheckPrecompileUpgrades(&config); err != nil {
+		t.Fatalf("known set rejected: %v", err)
+	}
+	config.PrecompileUpgrades = append(config.PrecompileUpgrades, params.PrecompileUpgrade{
+		Block: big.NewInt(10), Enable: []string{"nosuchset"},
+	})
+	if err := CheckPrecompileUpgrades(&config); err == nil {
+		t.Fatal("unknown set accepted")
+	}
+}
+
+// Tests that changing the precompile upgrades the chain has already passed is
+// incompatible, while rescheduling future ones isn't.
+func TestPrecompileUpgradeCompatible(t *testing.T) {
+	stored := *params.TestChainConfig
+	stored.PrecompileUpgrades = []params.PrecompileUpgrade{
+		{Block: big.NewInt(5), Enable: []string{"rip7212"}},
+		{Block: big.NewInt(20), Enable: []string{"eip2537"}},
+	}
+	future := stored
+	future.PrecompileUpgrades = []params.PrecompileUpgrade{
+		{Block: big.NewInt(5), Enable: []string{"rip7212"}},
+		{Block: big.NewInt(30), Enable: []string{"eip2537"}},
+	}
+	if err := stored.CheckCompatible(&future, 10); err != nil {
+		t.Fatalf("rescheduling a future upgrade is incompatible: %v", err)
+	}
+	past := stored
+	past.PrecompileUpgrades = []params.PrecompileUpgrade{
+		{Block: big.NewInt(5), Enable: []string{"eip2537"}},
+	}
+	err := stored.CheckCompatible(&past, 10)
+	if err == nil {
+		t.Fatal("changing a past upgrade is compatible")
+	}
+	if err.RewindTo != 4 {
+		t.Fatalf("have rewind to %d, want 4", err.RewindTo)
+	}
+}
+
+// Tests that the EVM runs the contracts of the sets a chain config enables,
+// from their activation block on.
+func TestPrecompileUpgradeCall(t *testing.T) {
+	p256, err := loadJson("p256Verify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := *params.TestChainConfig
+	config.PrecompileUpgrades = []params.PrecompileUpgrade{
+		{Block: big.NewInt(10), Enable: []string{"eip2537"}},
+		{Block: big.NewInt(20), Enable: []string{"rip7212"}},
+	}
+	tests := []struct {
+		addr     common.Address
+		input    []byte
+		expected []byte
+		gas      uint64
+		block    int64
+	}{
+		// the sum of two points at infinity
+		{common.BytesToAddress([]byte{10}), make([]byte, 256), make([]byte, 128), params.Bls12381G1AddGas, 10},
+		{common.BytesToAddress([]byte{0x01, 0x00}), common.Hex2Bytes(p256[0].Input), common.Hex2Bytes(p256[0].Expected), p256[0].Gas, 20},
+	}
+	oracle.UseMemory()
+	for i, test := range tests {
+		for _, number := range []int64{test.block - 1, test.block} {
+			header := types.Header{Number: big.NewInt(number), Root: types.EmptyRootHash}
+			statedb, err := state.New(header.Root, state.NewDatabase(header), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			blockCtx := BlockContext{
+				CanTransfer: func(StateDB, common.Address, *big.Int) bool { return true },
+				Transfer:    func(StateDB, common.Address, common.Address, *big.Int) {},
+				BlockNumber: header.Number,
+			}
+			evm := NewEVM(blockCtx, TxContext{}, statedb, &config, Config{})
+			ret, gas, err := evm.Call(AccountRef(common.Address{}), test.addr, test.input, 100000, new(big.Int))
+			if err != nil {
+				t.Fatalf("test %d: block %d: %v", i, number, err)
+			}
+			// before the upgrade the address is an empty account
+			want, wantGas := []byte(nil), uint64(100000)
+			if number >= test.block {
+				want, wantGas = test.expected, 100000-test.gas
+			}
+			if !bytes.Equal(ret, want) {
+				t.Errorf("test %d: block %d: have %x, want %x", i, number, ret, want)
+			}
+			if gas != wantGas {
+				t.Errorf("test %d: block %d: gas left %d, want %d", i, number, gas, wantGas)
+			}
+		}
+	}
+}
@@ -0,0 +1,465 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package ethapi executes messages against the state of a block the way the
+// eth_call family of RPC methods does, reading the state through the oracle.
+package ethapi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/tracers"
+)
+
+// OverrideAccount indicates the overriding fields of account during the execution
+// of a message call.
+// Note, state and stateDiff can't be specified at the same time. If state is
+// set, message execution will only use the data in the given state. Otherwise
+// if statDiff is set, all diff will be applied first and then execute the call
+// message.
+type OverrideAccount struct {
+	Nonce     *hexutil.Uint64              `json:"nonce"`
+	Code      *hexutil.Bytes               `json:"code"`
+	Balance   **hexutil.Big                `json:"balance"`
+	State     *map[common.Hash]common.Hash `json:"state"`
+	StateDiff *map[common.Hash]common.Hash `json:"stateDiff"`
+}
+
+// StateOverride is the collection of overridden accounts.
+type StateOverride map[common.Address]OverrideAccount
+
+// Apply overrides the fields of specified accounts into the given state.
+func (diff *StateOverride) Apply(state *state.StateDB) error {
+	if diff == nil {
+		return nil
+	}
+	for addr, account := range *diff {
+		// Override account nonce.
+		if account.Nonce != nil {
+			state.SetNonce(addr, uint64(*account.Nonce))
+		}
+		// Override account(contract) code.
+		if account.Code != nil {
+			state.SetCode(addr, *account.Code)
+		}
+		// Override account balance.
+		if account.Balance != nil {
+			state.SetBalance(addr, (*big.Int)(*account.Balance))
+		}
+		if account.State != nil && account.StateDiff != nil {
+			return fmt.Errorf("account %s has both 'state' and 'stateDiff'", addr.Hex())
+		}
+		// Replace entire state if caller requires.
+		if account.State != nil {
+			state.SetStorage(addr, *account.State)
+		}
+		// Apply state diff into specified accounts.
+		if account.StateDiff != nil {
+			for key, value := range *account.StateDiff {
+				state.SetState(addr, key, value)
+			}
+		}
+	}
+	return nil
+}
+
+// DoCall executes msg on top of the state of header, that is the state after
+// the block was applied, with the overrides applied first. Nothing is written
+// back, the state is only read through the oracle.
+//
+// Messages are usually built with types.NewMessage with isFake set, so that the
+// nonce isn't checked. Set vmConfig.NoBaseFee to allow zero gas prices on
+// London blocks.
+func DoCall(config *params.ChainConfig, header *types.Header, msg types.Message, overrides *StateOverride, vmConfig vm.Config) (*core.ExecutionResult, error) {
+	statedb, err := newState(header, overrides)
+	if err != nil {
+		return nil, err
+	}
+	return applyMessage(config, header, statedb, msg, vmConfig)
+}
+
+// newState opens the state after header with the overrides applied. Every
+// execution gets its own state, the proofs read by an earlier one stay in the
+// oracle.
+func newState(header *types.Header, overrides *StateOverride) (*state.StateDB, error) {
+	statedb, err := state.New(header.Root, state.NewDatabase(*header), nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := overrides.Apply(statedb); err != nil {
+		return nil, err
+	}
+	return statedb, nil
+}
+
+// applyMessage runs msg in the context of header against statedb.
+func applyMessage(config *params.ChainConfig, header *types.Header, statedb *state.StateDB, msg types.Message, vmConfig vm.Config) (*core.ExecutionResult, error) {
+	bc := core.NewBlockChain(config, header)
+	blockContext := core.NewEVMBlockContext(header, bc, nil)
+	evm := vm.NewEVM(blockContext, core.NewEVMTxContext(msg), statedb, config, vmConfig)
+
+	// The message gas limit is the only bound, not the block's
+	gp := new(core.GasPool).AddGas(math.MaxUint64)
+	result, err := core.ApplyMessage(evm, msg, gp)
+	// a node or code missing from the oracle leaves the result wrong
+	if dberr := statedb.Error(); dberr != nil {
+		return nil, fmt.Errorf("missing state: %w", dberr)
+	}
+	// so does a header missing for BLOCKHASH
+	if chainErr := bc.Error(); chainErr != nil {
+		return nil, fmt.Errorf("missing chain: %w", chainErr)
+	}
+	if err != nil {
+		return result, fmt.Errorf("err: %w (supplied gas %d)", err, msg.Gas())
+	}
+	return result, nil
+}
+
+// Call executes msg like DoCall and returns the return data of the call. A
+// reverted call returns an error carrying the decoded revert reason.
+func Call(config *params.ChainConfig, header *types.Header, msg types.Message, overrides *StateOverride, vmConfig vm.Config) (hexutil.Bytes, error) {
+	result, err := DoCall(config, header, msg, overrides, vmConfig)
+	if err != nil {
+		return nil, err
+	}
+	// If the result contains a revert reason, try to unpack and return it.
+	if len(result.Revert()) > 0 {
+		return nil, newRevertError(result)
+	}
+	return result.Return(), result.Err
+}
+
+// withGas returns msg with its gas limit replaced.
+func withGas(msg types.Message, gas uint64) types.Message {
+	return types.NewMessage(msg.From(), msg.To(), msg.Nonce(), msg.Value(), gas, msg.GasPrice(), msg.GasFeeCap(), msg.GasTipCap(), msg.Data(), msg.AccessList(), msg.IsFake())
+}
+
+// withAccessList returns msg with its access list replaced.
+func withAccessList(msg types.Message, accessList types.AccessList) types.Message {
+	return types.NewMessage(msg.From(), msg.To(), msg.Nonce(), msg.Value(), msg.Gas(), msg.GasPrice(), msg.GasFeeCap(), msg.GasTipCap(), msg.Data(), accessList, msg.IsFake())
+}
+
+// DoEstimateGas searches for the lowest gas limit msg executes with, like
+// eth_estimateGas. The search is bounded by the message gas limit, or the
+// block gas limit if that is below params.TxGas, by what the sender can pay
+// for and by gasCap if it isn't zero.
+func DoEstimateGas(config *params.ChainConfig, header *types.Header, msg types.Message, overrides *StateOverride, gasCap uint64) (hexutil.Uint64, error) {
+	// Binary search the gas requirement, as it may be higher than the amount used
+	var (
+		lo  uint64 = params.TxGas - 1
+		hi  uint64
+		cap uint64
+	)
+	// Determine the highest gas limit can be used during the estimation.
+	if msg.Gas() >= params.TxGas {
+		hi = msg.Gas()
+	} else {
+		hi = header.GasLimit
+	}
+	// Normalize the max fee per gas the call is willing to spend.
+	var feeCap *big.Int
+	if msg.GasFeeCap() != nil {
+		feeCap = msg.GasFeeCap()
+	} else if msg.GasPrice() != nil {
+		feeCap = msg.GasPrice()
+	} else {
+		feeCap = common.Big0
+	}
+	// Recap the highest gas limit with account's available balance.
+	if feeCap.BitLen() != 0 {
+		statedb, err := newState(header, overrides)
+		if err != nil {
+			return 0, err
+		}
+		balance := statedb.GetBalance(msg.From()) // from can't be nil
+		available := new(big.Int).Set(balance)
+		if msg.Value() != nil {
+			if msg.Value().Cmp(available) >= 0 {
+				return 0, core.ErrInsufficientFundsForTransfer
+			}
+			available.Sub(available, msg.Value())
+		}
+		allowance := new(big.Int).Div(available, feeCap)
+
+		// If the allowance is larger than maximum uint64, skip checking
+		if allowance.IsUint64() && hi > allowance.Uint64() {
+			log.Warn("Gas estimation capped by limited funds", "original", hi, "balance", balance,
+				"sent", msg.Value(), "maxFeePerGas", feeCap, "fundable", allowance)
+			hi = allowance.Uint64()
+		}
+	}
+	// Recap the highest gas allowance with specified gascap.
+	if gasCap != 0 && hi > gasCap {
+		log.Warn("Caller gas above allowance, capping", "requested", hi, "cap", gasCap)
+		hi = gasCap
+	}
+	cap = hi
+
+	// Create a helper to check if a gas allowance results in an executable transaction
+	executable := func(gas uint64) (bool, *core.ExecutionResult, error) {
+		result, err := DoCall(config, header, withGas(msg, gas), overrides, vm.Config{NoBaseFee: true})
+		if err != nil {
+			if errors.Is(err, core.ErrIntrinsicGas) {
+				return true, nil, nil // Special case, raise gas limit
+			}
+			return true, nil, err // Bail out
+		}
+		return result.Failed(), result, nil
+	}
+	// Execute the binary search and hone in on an executable gas limit
+	for lo+1 < hi {
+		mid := (hi + lo) / 2
+		failed, _, err := executable(mid)
+
+		// If the error is not nil(consensus error), it means the provided message
+		// call or transaction will never be accepted no matter how much gas it is
+		// assigned. Return the error directly, don't struggle any more.
+		if err != nil {
+			return 0, err
+		}
+		if failed {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	// Reject the transaction as invalid if it still fails at the highest allowance
+	if hi == cap {
+		failed, result, err := executable(hi)
+		if err != nil {
+			return 0, err
+		}
+		if failed {
+			if result != nil && result.Err != vm.ErrOutOfGas {
+				if len(result.Revert()) > 0 {
+					return 0, newRevertError(result)
+				}
+				return 0, result.Err
+			}
+			// Otherwise, the specified gas cap is too low
+			return 0, fmt.Errorf("gas required exceeds allowance (%d)", cap)
+		}
+	}
+	return hexutil.Uint64(hi), nil
+}
+
+// AccessList creates an access list for msg like eth_createAccessList. It
+// executes msg with the access list found so far until the list stops growing,
+// and returns it along with the gas used and the execution error of the last
+// run. A message without gas limit gets the block gas limit.
+func AccessList(config *params.ChainConfig, header *types.Header, msg types.Message, overrides *StateOverride) (acl types.AccessList, gasUsed uint64, vmErr error, err error) {
+	if msg.Gas() == 0 {
+		msg = withGas(msg, header.GasLimit)
+	}
+	// A contract creation goes to the address derived from the sender nonce
+	var to common.Address
+	if msg.To() != nil {
+		to = *msg.To()
+	} else {
+		statedb, err := newState(header, overrides)
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		to = crypto.CreateAddress(msg.From(), statedb.GetNonce(msg.From()))
+	}
+	// Retrieve the precompiles since they don't need to be added to the access list
+	isMerge := header.Difficulty.Sign() == 0
+	precompiles := vm.ActivePrecompiles(config.Rules(header.Number, isMerge))
+
+	// Create an initial tracer
+	prevTracer := tracers.NewAccessListTracer(msg.AccessList(), msg.From(), to, precompiles)
+	for {
+		// Retrieve the current access list to expand
+		accessList := prevTracer.AccessList()
+
+		// Apply the message with the access list tracer
+		tracer := tracers.NewAccessListTracer(accessList, msg.From(), to, precompiles)
+		vmConfig := vm.Config{Tracer: tracer, Debug: true, NoBaseFee: true}
+		res, err := DoCall(config, header, withAccessList(msg, accessList), overrides, vmConfig)
+		if err != nil {
+			return nil, 0, nil, fmt.Errorf("failed to apply message: %v", err)
+		}
+		if tracer.Equal(prevTracer) {
+			return accessList, res.UsedGas, res.Err, nil
+		}
+		prevTracer = tracer
+	}
+}
+
+// revertSelector is the selector of Error(string), used by solidity for
+// revert and require messages.
+var revertSelector = crypto.Keccak256([]byte("Error(string)"))[:4]
+
+// UnpackRevert resolves the abi-encoded revert reason. According to the solidity
+// spec https://solidity.readthedocs.io/en/latest/control-structures.html#revert,
+// the provided revert reason is abi-encoded as if it were a call to a function
+// `Error(string)`.
+func UnpackRevert(data []byte) (string, error) {
+	if len(data) < 4 || !bytes.Equal(data[:4], revertSelector) {
+		return "", errors.New("invalid data for unpacking")
+	}
+	data = data[4:]
+	// a single dynamic argument: the offset of the string, then its length
+	offset, err := readLength(data, 0)
+	if err != nil {
+		return "", err
+	}
+	length, err := readLength(data, offset)
+	if err != nil {
+		return "", err
+	}
+	start := offset + 32
+	if uint64(len(data))-start < length {
+		return "", errors.New("invalid data for unpacking")
+	}
+	return string(data[start : start+length]), nil
+}
+
+// readLength reads the abi-encoded word at offset as a length or offset into
+// data, which must fit the data.
+func readLength(data []byte, offset uint64) (uint64, error) {
+	if uint64(len(data)) < 32 || offset > uint64(len(data))-32 {
+		return 0, errors.New("invalid data for unpacking")
+	}
+	word := new(big.Int).SetBytes(data[offset : offset+32])
+	if !word.IsUint64() || word.Uint64() > uint64(len(data)) {
+		return 0, errors.New("invalid data for unpacking")
+	}
+	return word.Uint64(), nil
+}
+
+// revertError is an API error that encompasses an EVM revertal with JSON error
+// code and a binary data blob.
+type revertError struct {
+	error
+	reason string // revert reason hex encoded
+}
+
+func newRevertError(result *core.ExecutionResult) *revertError {
+	reason, errUnpack := UnpackRevert(result.Revert())
+	err := errors.New("execution reverted")
+	if errUnpack == nil {
+		err = fmt.Errorf("execution reverted: %v", reason)
+	}
+	return &revertError{
+		error:  err,
+		reason: hexutil.Encode(result.Revert()),
+	}
+}
+
+// ErrorCode returns the JSON error code for a revertal.
+// See: https://github.com/ethereum/wiki/wiki/JSON-RPC-Error-Codes-Improvement-Proposal
+func (e *revertError) ErrorCode() int {
+	return 3
+}
+
+// ErrorData returns the hex encoded revert reason.
+func (e *revertError) ErrorData() interface{} {
+	return e.reason
+}
+
+// AccountResult is the result of GetProof, as returned by eth_getProof.
+type AccountResult struct {
+	Address      common.Address  `json:"address"`
+	AccountProof []string        `json:"accountProof"`
+	Balance      *hexutil.Big    `json:"balance"`
+	CodeHash     common.Hash     `json:"codeHash"`
+	Nonce        hexutil.Uint64  `json:"nonce"`
+	StorageHash  common.Hash     `json:"storageHash"`
+	StorageProof []StorageResult `json:"storageProof"`
+}
+
+// StorageResult is the proof of a single storage slot in an AccountResult.
+type StorageResult struct {
+	Key   string       `json:"key"`
+	Value *hexutil.Big `json:"value"`
+	Proof []string     `json:"proof"`
+}
+
+// GetProof returns the Merkle proofs of the account and the storage slots
+// after header, like eth_getProof. The proofs are built from the nodes the
+// oracle provides.
+func GetProof(header *types.Header, address common.Address, storageKeys []string) (*AccountResult, error) {
+	statedb, err := newState(header, nil)
+	if err != nil {
+		return nil, err
+	}
+	storageTrie := statedb.StorageTrie(address)
+	storageHash := types.EmptyRootHash
+	codeHash := statedb.GetCodeHash(address)
+	storageProof := make([]StorageResult, len(storageKeys))
+
+	// if we have a storageTrie, (which means the account exists), we can update the storagehash
+	if storageTrie != nil {
+		storageHash = storageTrie.Hash()
+	} else {
+		// no storageTrie means the account does not exist, so the codeHash is the hash of an empty bytearray.
+		codeHash = crypto.Keccak256Hash(nil)
+	}
+
+	// create the proof for the storageKeys
+	for i, key := range storageKeys {
+		if storageTrie != nil {
+			// reading the slot first fetches the nodes the proof is made of
+			value := statedb.GetState(address, common.HexToHash(key))
+			proof, storageError := statedb.GetStorageProof(address, common.HexToHash(key))
+			if storageError != nil {
+				return nil, storageError
+			}
+			storageProof[i] = StorageResult{key, (*hexutil.Big)(value.Big()), toHexSlice(proof)}
+		} else {
+			storageProof[i] = StorageResult{key, &hexutil.Big{}, []string{}}
+		}
+	}
+
+	// create the accountProof
+	accountProof, proofErr := statedb.GetProof(address)
+	if proofErr != nil {
+		return nil, proofErr
+	}
+
+	return &AccountResult{
+		Address:      address,
+		AccountProof: toHexSlice(accountProof),
+		Balance:      (*hexutil.Big)(statedb.GetBalance(address)),
+		CodeHash:     codeHash,
+		Nonce:        hexutil.Uint64(statedb.GetNonce(address)),
+		StorageHash:  storageHash,
+		StorageProof: storageProof,
+	}, statedb.Error()
+}
+
+// toHexSlice creates a slice of hex-strings based on []byte.
+func toHexSlice(b [][]byte) []string {
+	r := make([]string, len(b))
+	for i := range b {
+		r[i] = hexutil.Encode(b[i])
+	}
+	return r
+}
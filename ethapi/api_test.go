@@ -0,0 +1,324 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package ethapi
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/oracle"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+var (
+	testConfig = params.AllEthashProtocolChanges
+	testSender = common.HexToAddress("0x5e4de4")
+	// returns the word in storage slot 0
+	loadCode = []byte{0x60, 0x00, 0x54, 0x60, 0x00, 0x52, 0x60, 0x20, 0x60, 0x00, 0xf3}
+)
+
+// newTestHeader commits the state setup builds into an emptied oracle and
+// returns a header with its root.
+func newTestHeader(t *testing.T, setup func(statedb *state.StateDB)) *types.Header {
+	oracle.UseMemory()
+	header := &types.Header{
+		Number:     big.NewInt(1),
+		Root:       types.EmptyRootHash,
+		Difficulty: big.NewInt(1),
+		GasLimit:   30000000,
+		BaseFee:    big.NewInt(params.InitialBaseFee),
+	}
+	statedb, err := state.New(header.Root, state.NewDatabase(*header), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	setup(statedb)
+	if header.Root, err = statedb.Commit(true); err != nil {
+		t.Fatal(err)
+	}
+	return header
+}
+
+// newTestMessage returns a message from testSender to to, without gas price,
+// whose nonce isn't checked.
+func newTestMessage(to common.Address, gas uint64, data []byte) types.Message {
+	return types.NewMessage(testSender, &to, 0, new(big.Int), gas, new(big.Int), new(big.Int), new(big.Int), data, nil, true)
+}
+
+// revertCode returns the code of a contract that reverts with data.
+func revertCode(data []byte) []byte {
+	// codecopy(0, 12, len(data)); revert(0, len(data))
+	code := []byte{0x60, byte(len(data)), 0x60, 0x0c, 0x60, 0x00, 0x39, 0x60, byte(len(data)), 0x60, 0x00, 0xfd}
+	return append(code, data...)
+}
+
+// revertData returns the abi encoding of Error(reason).
+func revertData(reason string) []byte {
+	data := append([]byte{}, revertSelector...)
+	data = append(data, common.BigToHash(big.NewInt(32)).Bytes()...)
+	data = append(data, common.BigToHash(big.NewInt(int64(len(reason)))).Bytes()...)
+	return append(data, common.RightPadBytes([]byte(reason), (len(reason)+31)/32*32)...)
+}
+
+func TestCall(t *testing.T) {
+	contract := common.HexToAddress("0xc0de")
+	other := common.HexToAddress("0x07e4")
+	header := newTestHeader(t, func(statedb *state.StateDB) {
+		statedb.SetCode(contract, loadCode)
+		statedb.SetState(contract, common.Hash{}, common.HexToHash("0x2a"))
+		statedb.SetState(contract, common.HexToHash("0x01"), common.HexToHash("0x05"))
+	})
+	slot1 := common.HexToHash("0x01")
+	tests := []struct {
+		to        common.Address
+		overrides *StateOverride
+		want      common.Hash
+		err       string
+	}{
+		{contract, nil, common.HexToHash("0x2a"), ""},
+		{contract, &StateOverride{contract: {StateDiff: &map[common.Hash]common.Hash{{}: common.HexToHash("0x07")}}}, common.HexToHash("0x07"), ""},
+		// the whole storage is replaced, slot 0 is empty then
+		{contract, &StateOverride{contract: {State: &map[common.Hash]common.Hash{slot1: common.HexToHash("0x09")}}}, common.Hash{}, ""},
+		{other, &StateOverride{other: {Code: (*hexutil.Bytes)(&loadCode)}}, common.Hash{}, ""},
+		{contract, &StateOverride{contract: {
+			State:     &map[common.Hash]common.Hash{},
+			StateDiff: &map[common.Hash]common.Hash{},
+		}}, common.Hash{}, "has both 'state' and 'stateDiff'"},
+		// the overrides of the earlier calls are gone
+		{contract, nil, common.HexToHash("0x2a"), ""},
+	}
+	for i, test := range tests {
+		ret, err := Call(testConfig, header, newTestMessage(test.to, 100000, nil), test.overrides, vm.Config{NoBaseFee: true})
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("test %d: error: have %v, want %q", i, err, test.err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("test %d: %v", i, err)
+		}
+		if common.BytesToHash(ret) != test.want || len(ret) != 32 {
+			t.Errorf("test %d: have %x, want %x", i, ret, test.want)
+		}
+	}
+}
+
+func TestCallBalanceOverride(t *testing.T) {
+	// returns the balance of the caller
+	code := []byte{0x33, 0x31, 0x60, 0x00, 0x52, 0x60, 0x20, 0x60, 0x00, 0xf3}
+	contract := common.HexToAddress("0xc0de")
+	header := newTestHeader(t, func(statedb *state.StateDB) {
+		statedb.SetCode(contract, code)
+	})
+	balance := (*hexutil.Big)(big.NewInt(1e18))
+	overrides := &StateOverride{testSender: {Balance: &balance}}
+	ret, err := Call(testConfig, header, newTestMessage(contract, 100000, nil), overrides, vm.Config{NoBaseFee: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have := new(big.Int).SetBytes(ret); have.Cmp(big.NewInt(1e18)) != 0 {
+		t.Errorf("balance: have %v, want 1e18", have)
+	}
+}
+
+func TestCallRevert(t *testing.T) {
+	contract := common.HexToAddress("0xc0de")
+	data := revertData("boom")
+	header := newTestHeader(t, func(statedb *state.StateDB) {
+		statedb.SetCode(contract, revertCode(data))
+	})
+	_, err := Call(testConfig, header, newTestMessage(contract, 100000, nil), nil, vm.Config{NoBaseFee: true})
+	var revert *revertError
+	if !errors.As(err, &revert) {
+		t.Fatalf("have %v, want a revert error", err)
+	}
+	if revert.Error() != "execution reverted: boom" {
+		t.Errorf("message: have %q, want %q", revert.Error(), "execution reverted: boom")
+	}
+	if revert.ErrorData() != hexutil.Encode(data) {
+		t.Errorf("data: have %v, want %s", revert.ErrorData(), hexutil.Encode(data))
+	}
+	// a message the sender can't pay for isn't executed at all
+	msg := types.NewMessage(testSender, &contract, 0, big.NewInt(1), 100000, new(big.Int), new(big.Int), new(big.Int), nil, nil, true)
+	if _, err := DoCall(testConfig, header, msg, nil, vm.Config{NoBaseFee: true}); err == nil {
+		t.Error("transfer without funds succeeded")
+	}
+}
+
+func TestCallMissingState(t *testing.T) {
+	contract := common.HexToAddress("0xc0de")
+	// blockhash(1); stop
+	header := newTestHeader(t, func(statedb *state.StateDB) {
+		statedb.SetCode(contract, []byte{0x60, 0x01, 0x40, 0x00})
+	})
+	// the state of another block isn't in the oracle
+	missing := types.CopyHeader(header)
+	missing.Root = common.HexToHash("0xdead")
+	if _, err := DoCall(testConfig, missing, newTestMessage(contract, 100000, nil), nil, vm.Config{NoBaseFee: true}); err == nil {
+		t.Error("call against a missing state succeeded")
+	}
+	// the ancestors BLOCKHASH reads aren't either
+	header.Number = big.NewInt(5)
+	if _, err := DoCall(testConfig, header, newTestMessage(contract, 100000, nil), nil, vm.Config{NoBaseFee: true}); err == nil {
+		t.Error("call with a missing ancestor header succeeded")
+	}
+}
+
+func TestUnpackRevert(t *testing.T) {
+	valid := revertData("revert reason")
+	tests := []struct {
+		data   []byte
+		reason string
+		ok     bool
+	}{
+		{valid, "revert reason", true},
+		{revertData(""), "", true},
+		{revertData(strings.Repeat("a", 40)), strings.Repeat("a", 40), true},
+		{nil, "", false},
+		{valid[:3], "", false},
+		{append([]byte{0x01, 0x02, 0x03, 0x04}, valid[4:]...), "", false},
+		{valid[:4+32], "", false},
+		// the string is longer than the data
+		{valid[:len(valid)-32], "", false},
+		// the offset points beyond the data
+		{append(append([]byte{}, valid[:4]...), append(common.BigToHash(big.NewInt(1000)).Bytes(), valid[36:]...)...), "", false},
+		{append(append([]byte{}, valid[:4]...), append(bytes.Repeat([]byte{0xff}, 32), valid[36:]...)...), "", false},
+	}
+	for i, test := range tests {
+		reason, err := UnpackRevert(test.data)
+		if (err == nil) != test.ok {
+			t.Errorf("test %d: error: have %v, want ok %v", i, err, test.ok)
+		}
+		if reason != test.reason {
+			t.Errorf("test %d: reason: have %q, want %q", i, reason, test.reason)
+		}
+	}
+}
+
+func TestEstimateGas(t *testing.T) {
+	var (
+		caller   = common.HexToAddress("0xca11e4")
+		callee   = common.HexToAddress("0xca11ee")
+		reverter = common.HexToAddress("0x4e7e47")
+	)
+	// call(gas, callee, 0, 0, 0, 0, 0) and revert if it failed, so that the
+	// 63/64 rule makes the estimate exceed the gas used
+	code := []byte{0x60, 0x00, 0x60, 0x00, 0x60, 0x00, 0x60, 0x00, 0x60, 0x00, 0x73}
+	code = append(code, callee.Bytes()...)
+	code = append(code, 0x5a, 0xf1, 0x15, 0x60, 0x26, 0x57, 0x00, 0x5b, 0x60, 0x00, 0x80, 0xfd)
+	header := newTestHeader(t, func(statedb *state.StateDB) {
+		statedb.SetCode(caller, code)
+		// sstore(0, 1)
+		statedb.SetCode(callee, []byte{0x60, 0x01, 0x60, 0x00, 0x55, 0x00})
+		statedb.SetCode(reverter, revertCode(revertData("boom")))
+	})
+	gas, err := DoEstimateGas(testConfig, header, newTestMessage(caller, 0, nil), nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := DoCall(testConfig, header, newTestMessage(caller, uint64(gas), nil), nil, vm.Config{NoBaseFee: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Failed() {
+		t.Errorf("call with the estimate %d failed: %v", gas, result.Err)
+	}
+	if result.UsedGas >= uint64(gas) {
+		t.Errorf("estimate %d not above the gas used %d", gas, result.UsedGas)
+	}
+	result, err = DoCall(testConfig, header, newTestMessage(caller, uint64(gas)-1, nil), nil, vm.Config{NoBaseFee: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Failed() {
+		t.Errorf("call below the estimate %d succeeded", gas)
+	}
+
+	// a plain transfer needs the intrinsic gas only
+	if gas, err := DoEstimateGas(testConfig, header, newTestMessage(common.HexToAddress("0x07e4"), 0, nil), nil, 0); err != nil || gas != hexutil.Uint64(params.TxGas) {
+		t.Errorf("transfer: have %d (%v), want %d", gas, err, params.TxGas)
+	}
+	// a revert at any gas limit is returned with its reason
+	_, err = DoEstimateGas(testConfig, header, newTestMessage(reverter, 0, nil), nil, 0)
+	if err == nil || err.Error() != "execution reverted: boom" {
+		t.Errorf("revert: have %v, want execution reverted: boom", err)
+	}
+	// the gas cap bounds the search
+	_, err = DoEstimateGas(testConfig, header, newTestMessage(callee, 0, nil), nil, 30000)
+	if err == nil || !strings.Contains(err.Error(), "gas required exceeds allowance (30000)") {
+		t.Errorf("capped: have %v, want gas required exceeds allowance", err)
+	}
+	// and so does what the sender can pay for
+	price := big.NewInt(params.InitialBaseFee)
+	msg := types.NewMessage(testSender, &callee, 0, new(big.Int), 0, price, price, price, nil, nil, true)
+	balance := (*hexutil.Big)(new(big.Int).Mul(price, big.NewInt(30000)))
+	_, err = DoEstimateGas(testConfig, header, msg, &StateOverride{testSender: {Balance: &balance}}, 0)
+	if err == nil || !strings.Contains(err.Error(), "gas required exceeds allowance (30000)") {
+		t.Errorf("unfunded: have %v, want gas required exceeds allowance", err)
+	}
+}
+
+func TestAccessList(t *testing.T) {
+	contract := common.HexToAddress("0xc0de")
+	other := common.HexToAddress("0x07e4")
+	// sload(1); balance(other)
+	code := []byte{0x60, 0x01, 0x54, 0x50, 0x73}
+	code = append(code, other.Bytes()...)
+	code = append(code, 0x31, 0x50, 0x00)
+	header := newTestHeader(t, func(statedb *state.StateDB) {
+		statedb.SetCode(contract, code)
+	})
+	acl, gasUsed, vmErr, err := AccessList(testConfig, header, newTestMessage(contract, 0, nil), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vmErr != nil {
+		t.Fatalf("execution failed: %v", vmErr)
+	}
+	want := map[common.Address][]common.Hash{
+		contract: {common.HexToHash("0x01")},
+		other:    {},
+	}
+	if len(acl) != len(want) {
+		t.Fatalf("access list: have %v, want %v", acl, want)
+	}
+	for _, tuple := range acl {
+		keys, ok := want[tuple.Address]
+		if !ok || len(tuple.StorageKeys) != len(keys) || (len(keys) > 0 && tuple.StorageKeys[0] != keys[0]) {
+			t.Errorf("access list: have %v, want %v", acl, want)
+		}
+	}
+	// the access list makes the accesses warm, so the message costs what it
+	// reports with the list attached
+	msg := withAccessList(newTestMessage(contract, header.GasLimit, nil), acl)
+	result, err := DoCall(testConfig, header, msg, nil, vm.Config{NoBaseFee: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.UsedGas != gasUsed {
+		t.Errorf("gas used: have %d, want %d", result.UsedGas, gasUsed)
+	}
+}
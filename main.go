@@ -1,23 +1,29 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"math/big"
 	"os"
 	"runtime/pprof"
 	"strconv"
+	"strings"
 
-	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/consensus/misc"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
 	"github.com/ethereum/go-ethereum/core"
-	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/stateless"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/oracle"
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/tracers"
+	"github.com/ethereum/go-ethereum/transition"
 	"github.com/ethereum/go-ethereum/trie"
 )
 
@@ -27,6 +33,81 @@ func check(err error) {
 	}
 }
 
+// prefetch fetches everything needed to verify the transition out of
+// blockNumber from the node and writes the inputs for the mips run.
+func prefetch(blockNumber int64) {
+	newNodeUrl, setNewNodeUrl := os.LookupEnv("NODE")
+	if setNewNodeUrl {
+		fmt.Println("override node url", newNodeUrl)
+		oracle.SetNodeUrl(newNodeUrl)
+	}
+	basedir := os.Getenv("BASEDIR")
+	if len(basedir) == 0 {
+		basedir = "/tmp/cannon"
+	}
+
+	pkw := oracle.PreimageKeyValueWriter{}
+	pkwtrie := trie.NewStackTrie(pkw)
+
+	oracle.SetRoot(fmt.Sprintf("%s/0_%d", basedir, blockNumber))
+	oracle.PrefetchBlock(big.NewInt(blockNumber), true, nil)
+	if len(os.Getenv("ANCESTOR_HASHES")) > 0 {
+		oracle.PrefetchAncestorHashes(big.NewInt(blockNumber))
+	}
+	// ship the ethash cache of a proof-of-work block instead of generating it
+	if len(os.Getenv("ETHASH_CACHE")) > 0 {
+		oracle.SetEthashCache(ethash.CacheBytes(uint64(blockNumber + 1)))
+	}
+	oracle.PrefetchBlock(big.NewInt(blockNumber+1), false, pkwtrie)
+	// fetch the state the reference node read up front
+	if len(os.Getenv("PREFETCH_WITNESS")) > 0 {
+		witness := oracle.TraceWitness(big.NewInt(blockNumber + 1))
+		oracle.PrefetchWitness(big.NewInt(blockNumber), witness)
+	}
+	hash, err := pkwtrie.Commit()
+	check(err)
+	fmt.Println("committed transactions", hash, err)
+}
+
+// verifyStateless executes the block in blockFile, RLP either raw or hex
+// encoded like debug_getRawBlock returns it, on top of the state in the
+// witness in witnessFile only.
+func verifyStateless(config *params.ChainConfig, vmconfig vm.Config, witnessFile string, blockFile string) {
+	data, err := ioutil.ReadFile(witnessFile)
+	check(err)
+	var witness stateless.Witness
+	check(json.Unmarshal(data, &witness))
+
+	data, err = ioutil.ReadFile(blockFile)
+	check(err)
+	if enc := strings.TrimSpace(string(data)); strings.HasPrefix(enc, "0x") {
+		data, err = hexutil.Decode(enc)
+		check(err)
+	}
+	var block types.Block
+	check(rlp.DecodeBytes(data, &block))
+
+	result, err := transition.ProcessWitness(context.Background(), config, nil, &block, &witness, vmconfig)
+	check(err)
+	fmt.Println("stateless block", block.Number(), "with", len(block.Transactions()), "transactions verified, root", result.Root)
+}
+
+// statelessChainConfig returns the configuration of the chain with the given
+// ID, the witness doesn't tell. No ID stands for the main network.
+func statelessChainConfig(chainID string) (*params.ChainConfig, error) {
+	if chainID == "" {
+		return params.MainnetChainConfig, nil
+	}
+	id, ok := new(big.Int).SetString(chainID, 0)
+	if !ok {
+		return nil, fmt.Errorf("bad chain ID %q", chainID)
+	}
+	if config := params.ChainConfigByID(id); config != nil {
+		return config, nil
+	}
+	return nil, fmt.Errorf("%w: chain ID %v", transition.ErrUnknownChain, id)
+}
+
 func main() {
 	if len(os.Args) > 2 {
 		f, err := os.Create(os.Args[2])
@@ -39,118 +120,86 @@ func main() {
 
 	// non mips
 	if len(os.Args) > 1 {
-		newNodeUrl, setNewNodeUrl := os.LookupEnv("NODE")
-		if setNewNodeUrl {
-			fmt.Println("override node url", newNodeUrl)
-			oracle.SetNodeUrl(newNodeUrl)
-		}
-		basedir := os.Getenv("BASEDIR")
-		if len(basedir) == 0 {
-			basedir = "/tmp/cannon"
-		}
-
-		pkw := oracle.PreimageKeyValueWriter{}
-		pkwtrie := trie.NewStackTrie(pkw)
-
 		blockNumber, _ := strconv.Atoi(os.Args[1])
-		// TODO: get the chainid
-		oracle.SetRoot(fmt.Sprintf("%s/0_%d", basedir, blockNumber))
-		oracle.PrefetchBlock(big.NewInt(int64(blockNumber)), true, nil)
-		oracle.PrefetchBlock(big.NewInt(int64(blockNumber)+1), false, pkwtrie)
-		hash, err := pkwtrie.Commit()
-		check(err)
-		fmt.Println("committed transactions", hash, err)
+		prefetch(int64(blockNumber))
 	}
 
 	// init secp256k1BytePoints
 	crypto.S256()
 
-	// get inputs
-	inputBytes := oracle.Preimage(oracle.InputHash())
-	var inputs [6]common.Hash
-	for i := 0; i < len(inputs); i++ {
-		inputs[i] = common.BytesToHash(inputBytes[i*0x20 : i*0x20+0x20])
-	}
-
-	// read start block header
-	var parent types.Header
-	check(rlp.DecodeBytes(oracle.Preimage(inputs[0]), &parent))
-
-	// read header
-	var newheader types.Header
-	// from parent
-	newheader.ParentHash = parent.Hash()
-	newheader.Number = big.NewInt(0).Add(parent.Number, big.NewInt(1))
-	newheader.BaseFee = misc.CalcBaseFee(params.MainnetChainConfig, &parent)
-
-	// from input oracle
-	newheader.TxHash = inputs[1]
-	newheader.Coinbase = common.BigToAddress(inputs[2].Big())
-	newheader.UncleHash = inputs[3]
-	newheader.GasLimit = inputs[4].Big().Uint64()
-	newheader.Time = inputs[5].Big().Uint64()
-
-	bc := core.NewBlockChain(&parent)
-	database := state.NewDatabase(parent)
-	statedb, _ := state.New(parent.Root, database, nil)
 	vmconfig := vm.Config{}
-	processor := core.NewStateProcessor(params.MainnetChainConfig, bc, bc.Engine())
-	fmt.Println("processing state:", parent.Number, "->", newheader.Number)
-
-	newheader.Difficulty = bc.Engine().CalcDifficulty(bc, newheader.Time, &parent)
-
-	// read txs
-	//traverseStackTrie(newheader.TxHash)
-
-	//fmt.Println(fn)
-	//fmt.Println(txTrieRoot)
-	var txs []*types.Transaction
-
-	triedb := trie.NewDatabase(parent)
-	tt, _ := trie.New(newheader.TxHash, &triedb)
-	tni := tt.NodeIterator([]byte{})
-	for tni.Next(true) {
-		//fmt.Println(tni.Hash(), tni.Leaf(), tni.Path(), tni.Error())
-		if tni.Leaf() {
-			tx := types.Transaction{}
-			var rlpKey uint64
-			check(rlp.DecodeBytes(tni.LeafKey(), &rlpKey))
-			check(tx.UnmarshalBinary(tni.LeafBlob()))
-			// TODO: resize an array in go?
-			for uint64(len(txs)) <= rlpKey {
-				txs = append(txs, nil)
+	// compare the receipts with the node's to find the first bad transaction
+	diagnose := len(os.Getenv("DIAGNOSE")) > 0
+	traces := make(map[int]json.RawMessage)
+	// trace every transaction, the output matches debug_traceTransaction
+	if tracerName, ok := os.LookupEnv("TRACER"); ok {
+		tracerConfig := json.RawMessage(os.Getenv("TRACER_CONFIG"))
+		tracer, err := tracers.NewBlockTracer(tracerName, tracerConfig, func(index int, result json.RawMessage, err error) {
+			check(err)
+			if diagnose {
+				traces[index] = result
+			} else {
+				fmt.Println("trace", index, string(result))
 			}
-			txs[rlpKey] = &tx
-		}
+		})
+		check(err)
+		vmconfig.Debug = true
+		vmconfig.Tracer = tracer
 	}
-	fmt.Println("read", len(txs), "transactions")
-	// TODO: OMG the transaction ordering isn't fixed
 
-	var uncles []*types.Header
-	check(rlp.DecodeBytes(oracle.Preimage(newheader.UncleHash), &uncles))
+	// verify a block with nothing but a witness, e.g. in CI
+	if witnessFile, ok := os.LookupEnv("STATELESS"); ok {
+		config, err := statelessChainConfig(os.Getenv("CHAIN_ID"))
+		check(err)
+		verifyStateless(config, vmconfig, witnessFile, os.Getenv("BLOCK"))
+		return
+	}
 
-	var receipts []*types.Receipt
-	block := types.NewBlock(&newheader, txs, uncles, receipts, trie.NewStackTrie(nil))
-	fmt.Println("made block, parent:", newheader.ParentHash)
+	inputs := transition.ReadInputs()
+	config, err := inputs.ChainConfig()
+	check(err)
 
-	// if this is correct, the trie is working
-	// TODO: it's the previous block now
-	if newheader.TxHash != block.Header().TxHash {
-		panic("wrong transactions for block")
+	// record what the block read, to run it again without a node
+	process := transition.Process
+	witnessPath, writeWitness := os.LookupEnv("WITNESS")
+	if writeWitness {
+		process = transition.ProcessWithWitness
 	}
-	if newheader.UncleHash != block.Header().UncleHash {
-		panic("wrong uncles for block " + newheader.UncleHash.String() + " " + block.Header().UncleHash.String())
+	result, err := process(context.Background(), config, nil, inputs, vmconfig)
+	check(err)
+	parent, newheader, txs := result.Parent, result.Block.Header(), result.Block.Transactions()
+	fmt.Println("processed state:", parent.Number, "->", newheader.Number, "with", len(txs), "transactions")
+	fmt.Println("receipt count", len(result.Receipts), "hash", result.ReceiptHash)
+	if diagnose {
+		if want := oracle.ReferenceReceipts(newheader.Number); want != nil {
+			if index, err := core.CompareReceipts(result.Receipts, want); err != nil {
+				fmt.Println("first divergent transaction", index, err)
+				if index < len(txs) {
+					fmt.Println("transaction hash", txs[index].Hash())
+				}
+				if trace, ok := traces[index]; ok {
+					fmt.Println("trace", index, string(trace))
+				}
+			} else {
+				fmt.Println("all", len(result.Receipts), "receipts match")
+			}
+		}
 	}
-
-	// validateState is more complete, gas used + bloom also
-	receipts, _, _, err := processor.Process(block, statedb, vmconfig)
-	receiptSha := types.DeriveSha(types.Receipts(receipts), trie.NewStackTrie(nil))
-	if err != nil {
-		log.Fatal(err)
+	// write what the block changed for analysis
+	if path, ok := os.LookupEnv("STATE_DIFF"); ok {
+		diff, err := result.StateDiff()
+		check(err)
+		data, err := json.MarshalIndent(diff, "", "  ")
+		check(err)
+		check(ioutil.WriteFile(path, data, 0644))
+		fmt.Println("state diff of", len(diff), "accounts written to", path)
 	}
-	newroot := statedb.IntermediateRoot(bc.Config().IsEIP158(newheader.Number))
-
-	fmt.Println("receipt count", len(receipts), "hash", receiptSha)
-	fmt.Println("process done with hash", parent.Root, "->", newroot)
-	oracle.Output(newroot, receiptSha)
+	if writeWitness {
+		data, err := json.Marshal(result.Witness)
+		check(err)
+		check(ioutil.WriteFile(witnessPath, data, 0644))
+		fmt.Println("witness with", len(result.Witness.State), "nodes and", len(result.Witness.Codes), "codes written to", witnessPath)
+	}
+	fmt.Println("process done with hash", parent.Root, "->", result.Root)
+	oracle.Output(result.Root, result.ReceiptHash)
 }
@@ -57,6 +57,8 @@ type Header struct {
 	MixDigest   *common.Hash      `json:"mixHash"`
 	Nonce       *types.BlockNonce `json:"nonce"`
 	BaseFee     *hexutil.Big      `json:"baseFeePerGas" rlp:"optional"`
+	// not part of the header, reported by the node
+	TotalDifficulty *hexutil.Big `json:"totalDifficulty"`
 	// transactions
 	Transactions []SendTxArgs `json:"transactions"`
 }
@@ -154,3 +156,44 @@ func (args *SendTxArgs) ToTransaction() *types.Transaction {
 	}
 	return types.NewTx(data)
 }
+
+// LogResult is a log as returned by eth_getTransactionReceipt.
+type LogResult struct {
+	Address common.Address `json:"address"`
+	Topics  []common.Hash  `json:"topics"`
+	Data    hexutil.Bytes  `json:"data"`
+}
+
+// ReceiptResult is a receipt as returned by eth_getTransactionReceipt.
+type ReceiptResult struct {
+	Type              hexutil.Uint64  `json:"type"`
+	Root              hexutil.Bytes   `json:"root"`
+	Status            *hexutil.Uint64 `json:"status"`
+	CumulativeGasUsed hexutil.Uint64  `json:"cumulativeGasUsed"`
+	LogsBloom         types.Bloom     `json:"logsBloom"`
+	Logs              []LogResult     `json:"logs"`
+	TransactionHash   common.Hash     `json:"transactionHash"`
+	ContractAddress   *common.Address `json:"contractAddress"`
+	GasUsed           hexutil.Uint64  `json:"gasUsed"`
+}
+
+func (dec *ReceiptResult) ToReceipt() *types.Receipt {
+	r := &types.Receipt{
+		Type:              uint8(dec.Type),
+		PostState:         dec.Root,
+		CumulativeGasUsed: uint64(dec.CumulativeGasUsed),
+		Bloom:             dec.LogsBloom,
+		TxHash:            dec.TransactionHash,
+		GasUsed:           uint64(dec.GasUsed),
+	}
+	if dec.Status != nil {
+		r.Status = uint64(*dec.Status)
+	}
+	if dec.ContractAddress != nil {
+		r.ContractAddress = *dec.ContractAddress
+	}
+	for _, l := range dec.Logs {
+		r.Logs = append(r.Logs, &types.Log{Address: l.Address, Topics: l.Topics, Data: l.Data, TxHash: dec.TransactionHash})
+	}
+	return r
+}
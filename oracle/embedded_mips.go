@@ -90,10 +90,18 @@ func Preimage(hash common.Hash) []byte {
 // these are stubs in embedded world
 func SetNodeUrl(newNodeUrl string)                                                        {}
 func SetRoot(newRoot string)                                                              {}
+func UseMemory()                                                                          {}
 func PrefetchStorage(*big.Int, common.Address, common.Hash, func(map[common.Hash][]byte)) {}
 func PrefetchAccount(*big.Int, common.Address, func(map[common.Hash][]byte))              {}
 func PrefetchCode(blockNumber *big.Int, addrHash common.Hash)                             {}
 func PrefetchBlock(blockNumber *big.Int, startBlock bool, hasher types.TrieHasher)        {}
+func SetEthashCache(cache []byte)                                                         {}
+func PrefetchUncles(blockNumber *big.Int)                                                 {}
+func PrefetchHeader(blockHash common.Hash)                                                {}
+func PrefetchAncestorHashes(blockNumber *big.Int)                                         {}
+func PrefetchWitness(blockNumber *big.Int, witness Witness)                               {}
+func TraceWitness(blockNumber *big.Int) Witness                                           { return nil }
+func ReferenceReceipts(blockNumber *big.Int) []*types.Receipt                             { return nil }
 
 // KeyValueWriter wraps the Put method of a backing data store.
 type PreimageKeyValueWriter struct{}
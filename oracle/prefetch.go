@@ -14,6 +14,7 @@ import (
 	"math/big"
 	"net/http"
 	"os"
+	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -53,6 +54,17 @@ type jsonrespt struct {
 	Result  Header `json:"result"`
 }
 
+// jsonresph is a block without transaction bodies, the transaction hashes
+// shadow the full transactions of the embedded Header
+type jsonresph struct {
+	Jsonrpc string `json:"jsonrpc"`
+	Id      uint64 `json:"id"`
+	Result  struct {
+		Header
+		Transactions []common.Hash `json:"transactions"`
+	} `json:"result"`
+}
+
 // Result structs for GetProof
 type AccountResult struct {
 	Address      common.Address  `json:"address"`
@@ -111,9 +123,11 @@ func getAPI(jsonData []byte) io.Reader {
 	if cacheExists(key) {
 		return bytes.NewReader(cacheRead(key))
 	}
-	resp, _ := http.Post(nodeUrl, "application/json", bytes.NewBuffer(jsonData))
+	resp, err := http.Post(nodeUrl, "application/json", bytes.NewBuffer(jsonData))
+	check(err)
 	defer resp.Body.Close()
-	ret, _ := ioutil.ReadAll(resp.Body)
+	ret, err := ioutil.ReadAll(resp.Body)
+	check(err)
 	cacheWrite(key, ret)
 	return bytes.NewReader(ret)
 }
@@ -121,70 +135,208 @@ func getAPI(jsonData []byte) io.Reader {
 var unhashMap = make(map[common.Hash]common.Address)
 
 func unhash(addrHash common.Hash) common.Address {
+	lock.Lock()
+	defer lock.Unlock()
 	return unhashMap[addrHash]
 }
 
-var cached = make(map[string]bool)
+func setUnhash(addrHash common.Hash, addr common.Address) {
+	lock.Lock()
+	defer lock.Unlock()
+	unhashMap[addrHash] = addr
+}
+
+// fetches holds the keys fetched or being fetched, a caller asking for a key
+// another goroutine is fetching waits for it.
+var fetches = make(map[string]*sync.Once)
+
+// fetchOnce calls fetch unless key was fetched already.
+func fetchOnce(key string, fetch func()) {
+	lock.Lock()
+	once, ok := fetches[key]
+	if !ok {
+		once = new(sync.Once)
+		fetches[key] = once
+	}
+	lock.Unlock()
+	once.Do(fetch)
+}
+
+// fetched reports whether key was fetched or is being fetched.
+func fetched(key string) bool {
+	lock.Lock()
+	defer lock.Unlock()
+	_, ok := fetches[key]
+	return ok
+}
+
+// setFetched records key as fetched along with another request.
+func setFetched(key string) {
+	fetchOnce(key, func() {})
+}
+
+var emptyCodeHash = crypto.Keccak256Hash(nil)
 
 func PrefetchStorage(blockNumber *big.Int, addr common.Address, skey common.Hash, postProcess func(map[common.Hash][]byte)) {
 	key := fmt.Sprintf("proof_%d_%s_%s", blockNumber, addr, skey)
-	if cached[key] {
+	if memoryOnly() {
 		return
 	}
-	cached[key] = true
-
-	ap := getProofAccount(blockNumber, addr, skey, true)
-	//fmt.Println("PrefetchStorage", blockNumber, addr, skey, len(ap))
-	newPreimages := make(map[common.Hash][]byte)
-	for _, s := range ap {
-		ret, _ := hex.DecodeString(s[2:])
-		hash := crypto.Keccak256Hash(ret)
-		//fmt.Println("   ", i, hash)
-		newPreimages[hash] = ret
-	}
+	fetchOnce(key, func() {
+		ap := getProofAccount(blockNumber, addr, skey, true)
+		//fmt.Println("PrefetchStorage", blockNumber, addr, skey, len(ap))
+		newPreimages := make(map[common.Hash][]byte)
+		for _, s := range ap {
+			ret, _ := hex.DecodeString(s[2:])
+			hash := crypto.Keccak256Hash(ret)
+			//fmt.Println("   ", i, hash)
+			newPreimages[hash] = ret
+		}
 
-	if postProcess != nil {
-		postProcess(newPreimages)
-	}
+		if postProcess != nil {
+			postProcess(newPreimages)
+		}
 
-	for hash, val := range newPreimages {
-		preimages[hash] = val
-	}
+		for hash, val := range newPreimages {
+			putPreimage(hash, val)
+		}
+	})
 }
 
 func PrefetchAccount(blockNumber *big.Int, addr common.Address, postProcess func(map[common.Hash][]byte)) {
 	key := fmt.Sprintf("proof_%d_%s", blockNumber, addr)
-	if cached[key] {
+	if memoryOnly() {
 		return
 	}
-	cached[key] = true
-
-	ap := getProofAccount(blockNumber, addr, common.Hash{}, false)
-	newPreimages := make(map[common.Hash][]byte)
-	for _, s := range ap {
-		ret, _ := hex.DecodeString(s[2:])
-		hash := crypto.Keccak256Hash(ret)
-		newPreimages[hash] = ret
+	fetchOnce(key, func() {
+		ap := getProofAccount(blockNumber, addr, common.Hash{}, false)
+		newPreimages := make(map[common.Hash][]byte)
+		for _, s := range ap {
+			ret, _ := hex.DecodeString(s[2:])
+			hash := crypto.Keccak256Hash(ret)
+			newPreimages[hash] = ret
+		}
+
+		if postProcess != nil {
+			postProcess(newPreimages)
+		}
+
+		for hash, val := range newPreimages {
+			putPreimage(hash, val)
+		}
+	})
+}
+
+// PrefetchWitness fetches the proofs of all accounts and storage slots of the
+// witness in a single batch request. The later lazy prefetches of the same
+// entries are skipped.
+func PrefetchWitness(blockNumber *big.Int, witness Witness) {
+	var (
+		reqs  []jsonreq
+		addrs []common.Address
+		slots [][]common.Hash
+	)
+	// Sorted, so that the batch is cached under the same key every time
+	for _, addr := range witness.Addresses() {
+		key := fmt.Sprintf("proof_%d_%s", blockNumber, addr)
+		skeys := []common.Hash{}
+		for _, skey := range witness.Slots(addr) {
+			if !fetched(fmt.Sprintf("%s_%s", key, skey)) {
+				skeys = append(skeys, skey)
+			}
+		}
+		if fetched(key) && len(skeys) == 0 {
+			continue
+		}
+		setUnhash(crypto.Keccak256Hash(addr[:]), addr)
+
+		r := jsonreq{Jsonrpc: "2.0", Method: "eth_getProof", Id: uint64(len(reqs))}
+		r.Params = make([]interface{}, 3)
+		r.Params[0] = addr
+		r.Params[1] = skeys
+		r.Params[2] = fmt.Sprintf("0x%x", blockNumber.Int64())
+		reqs = append(reqs, r)
+		addrs = append(addrs, addr)
+		slots = append(slots, skeys)
+	}
+	if len(reqs) == 0 {
+		return
+	}
+	jsonData, err := json.Marshal(reqs)
+	check(err)
+	var jrs []jsonresp
+	check(json.NewDecoder(getAPI(jsonData)).Decode(&jrs))
+	if len(jrs) != len(reqs) {
+		check(fmt.Errorf("batch of %d proofs answered with %d", len(reqs), len(jrs)))
 	}
 
-	if postProcess != nil {
-		postProcess(newPreimages)
+	// The responses of a batch may come in any order
+	for _, jr := range jrs {
+		if jr.Id >= uint64(len(reqs)) {
+			check(fmt.Errorf("unknown proof response id %d", jr.Id))
+		}
+		addr := addrs[jr.Id]
+		proofs := [][]string{jr.Result.AccountProof}
+		for _, sp := range jr.Result.StorageProof {
+			proofs = append(proofs, sp.Proof)
+		}
+		for _, proof := range proofs {
+			for _, s := range proof {
+				ret, _ := hex.DecodeString(s[2:])
+				putPreimage(crypto.Keccak256Hash(ret), ret)
+			}
+		}
+		key := fmt.Sprintf("proof_%d_%s", blockNumber, addr)
+		setFetched(key)
+		for _, skey := range slots[jr.Id] {
+			setFetched(fmt.Sprintf("%s_%s", key, skey))
+		}
+		if jr.Result.CodeHash != emptyCodeHash && jr.Result.CodeHash != (common.Hash{}) {
+			PrefetchCode(blockNumber, crypto.Keccak256Hash(addr[:]))
+		}
 	}
+}
 
-	for hash, val := range newPreimages {
-		preimages[hash] = val
+// TraceWitness asks the node for the prestate of every transaction of the
+// given block and returns the accounts and storage slots they read.
+func TraceWitness(blockNumber *big.Int) Witness {
+	r := jsonreq{Jsonrpc: "2.0", Method: "debug_traceBlockByNumber", Id: 1}
+	r.Params = make([]interface{}, 2)
+	r.Params[0] = fmt.Sprintf("0x%x", blockNumber.Int64())
+	r.Params[1] = map[string]string{"tracer": "prestateTracer"}
+	jsonData, err := json.Marshal(r)
+	check(err)
+
+	var jr struct {
+		Result []struct {
+			Result map[common.Address]struct {
+				Storage map[common.Hash]common.Hash `json:"storage"`
+			} `json:"result"`
+		} `json:"result"`
 	}
+	check(json.NewDecoder(getAPI(jsonData)).Decode(&jr))
+
+	witness := make(Witness)
+	for _, tx := range jr.Result {
+		for addr, acc := range tx.Result {
+			witness.Add(addr)
+			for slot := range acc.Storage {
+				witness.Add(addr, slot)
+			}
+		}
+	}
+	return witness
 }
 
 func PrefetchCode(blockNumber *big.Int, addrHash common.Hash) {
 	key := fmt.Sprintf("code_%d_%s", blockNumber, addrHash)
-	if cached[key] {
+	if memoryOnly() {
 		return
 	}
-	cached[key] = true
-	ret := getProvedCodeBytes(blockNumber, addrHash)
-	hash := crypto.Keccak256Hash(ret)
-	preimages[hash] = ret
+	fetchOnce(key, func() {
+		ret := getProvedCodeBytes(blockNumber, addrHash)
+		putPreimage(crypto.Keccak256Hash(ret), ret)
+	})
 }
 
 var inputhash common.Hash
@@ -193,7 +345,7 @@ func InputHash() common.Hash {
 	return inputhash
 }
 
-var inputs [6]common.Hash
+var inputs [12]common.Hash
 var outputs [2]common.Hash
 
 func Output(output common.Hash, receipts common.Hash) {
@@ -253,7 +405,121 @@ func prefetchUncles(blockHash common.Hash, uncleHash common.Hash, hasher types.T
 		panic("wrong uncle hash")
 	}
 
-	preimages[hash] = unclesRlp
+	putPreimage(hash, unclesRlp)
+}
+
+// PrefetchHeader makes the header of the given block available as the preimage
+// of its hash. Unlike PrefetchBlock it leaves the inputs untouched.
+func PrefetchHeader(blockHash common.Hash) {
+	key := fmt.Sprintf("header_%s", blockHash)
+	if _, ok := readPreimage(blockHash); ok || memoryOnly() || fetched(key) {
+		return
+	}
+	setFetched(key)
+
+	r := jsonreq{Jsonrpc: "2.0", Method: "eth_getBlockByHash", Id: 1}
+	r.Params = make([]interface{}, 2)
+	r.Params[0] = blockHash.Hex()
+	r.Params[1] = false
+	jsonData, err := json.Marshal(r)
+	check(err)
+
+	jr := jsonresph{}
+	check(json.NewDecoder(getAPI(jsonData)).Decode(&jr))
+	if jr.Result.ParentHash == nil {
+		// unknown block, leave the preimage missing
+		return
+	}
+	blockHeader := jr.Result.ToHeader()
+
+	blockHeaderRlp, err := rlp.EncodeToBytes(&blockHeader)
+	check(err)
+	hash := crypto.Keccak256Hash(blockHeaderRlp)
+	if hash != blockHash {
+		panic("wrong header hash")
+	}
+	putPreimage(hash, blockHeaderRlp)
+}
+
+// PrefetchAncestorHashes makes the hashes of the given block and its 255
+// ancestors available as one preimage, newest first, and commits to it in the
+// inputs. It must be called after the start block is prefetched.
+func PrefetchAncestorHashes(blockNumber *big.Int) {
+	hash := inputs[0]
+	hashes := make([]byte, 0, 256*common.HashLength)
+	for n := blockNumber.Int64(); ; n-- {
+		hashes = append(hashes, hash.Bytes()...)
+		if n == 0 || len(hashes) == cap(hashes) {
+			break
+		}
+		PrefetchHeader(hash)
+		var header types.Header
+		enc, _ := readPreimage(hash)
+		check(rlp.DecodeBytes(enc, &header))
+		hash = header.ParentHash
+	}
+	accHash := crypto.Keccak256Hash(hashes)
+	putPreimage(accHash, hashes)
+	inputs[11] = accHash
+}
+
+// SetEthashCache makes the ethash verification cache of the block available as
+// a preimage and commits to it in the inputs, so the mips run needn't generate
+// it. It must be called before the block is prefetched.
+func SetEthashCache(cache []byte) {
+	hash := crypto.Keccak256Hash(cache)
+	putPreimage(hash, cache)
+	inputs[8] = hash
+}
+
+// ReferenceReceipts fetches the receipts the node has for the transactions of
+// the given block, in transaction order.
+func ReferenceReceipts(blockNumber *big.Int) []*types.Receipt {
+	r := jsonreq{Jsonrpc: "2.0", Method: "eth_getBlockByNumber", Id: 1}
+	r.Params = make([]interface{}, 2)
+	r.Params[0] = fmt.Sprintf("0x%x", blockNumber.Int64())
+	r.Params[1] = false
+	jsonData, err := json.Marshal(r)
+	check(err)
+	jr := jsonresph{}
+	check(json.NewDecoder(getAPI(jsonData)).Decode(&jr))
+
+	receipts := make([]*types.Receipt, 0, len(jr.Result.Transactions))
+	for _, txHash := range jr.Result.Transactions {
+		r := jsonreq{Jsonrpc: "2.0", Method: "eth_getTransactionReceipt", Id: 1}
+		r.Params = make([]interface{}, 1)
+		r.Params[0] = txHash.Hex()
+		jsonData, err := json.Marshal(r)
+		check(err)
+		var jr struct {
+			Result ReceiptResult `json:"result"`
+		}
+		check(json.NewDecoder(getAPI(jsonData)).Decode(&jr))
+		receipts = append(receipts, jr.Result.ToReceipt())
+	}
+	return receipts
+}
+
+// PrefetchUncles makes the uncle list of the given block available as the
+// preimage of its uncle hash.
+func PrefetchUncles(blockNumber *big.Int) {
+	key := fmt.Sprintf("uncles_%d", blockNumber)
+	if fetched(key) {
+		return
+	}
+	setFetched(key)
+
+	r := jsonreq{Jsonrpc: "2.0", Method: "eth_getBlockByNumber", Id: 1}
+	r.Params = make([]interface{}, 2)
+	r.Params[0] = fmt.Sprintf("0x%x", blockNumber.Int64())
+	r.Params[1] = true
+	jsonData, err := json.Marshal(r)
+	check(err)
+
+	jr := jsonrespt{}
+	check(json.NewDecoder(getAPI(jsonData)).Decode(&jr))
+	blockHeader := jr.Result.ToHeader()
+	prefetchUncles(blockHeader.Hash(), blockHeader.UncleHash, nil)
 }
 
 func PrefetchBlock(blockNumber *big.Int, startBlock bool, hasher types.TrieHasher) {
@@ -277,10 +543,14 @@ func PrefetchBlock(blockNumber *big.Int, startBlock bool, hasher types.TrieHashe
 		blockHeaderRlp, err := rlp.EncodeToBytes(&blockHeader)
 		check(err)
 		hash := crypto.Keccak256Hash(blockHeaderRlp)
-		preimages[hash] = blockHeaderRlp
+		putPreimage(hash, blockHeaderRlp)
 		emptyHash := common.Hash{}
 		if inputs[0] == emptyHash {
 			inputs[0] = hash
+			if jr.Result.TotalDifficulty != nil {
+				inputs[9] = common.BigToHash((*big.Int)(jr.Result.TotalDifficulty))
+			}
+			inputs[6] = common.BigToHash(new(big.Int).SetUint64(getChainID()))
 		}
 		return
 	}
@@ -295,6 +565,13 @@ func PrefetchBlock(blockNumber *big.Int, startBlock bool, hasher types.TrieHashe
 	inputs[3] = blockHeader.UncleHash
 	inputs[4] = common.BigToHash(big.NewInt(int64(blockHeader.GasLimit)))
 	inputs[5] = common.BigToHash(big.NewInt(int64(blockHeader.Time)))
+	inputs[10] = blockHeader.MixDigest
+
+	// the verification commits to the whole header
+	blockHeaderRlp, err := rlp.EncodeToBytes(&blockHeader)
+	check(err)
+	inputs[7] = crypto.Keccak256Hash(blockHeaderRlp)
+	putPreimage(inputs[7], blockHeaderRlp)
 
 	// save the inputs
 	saveinput := make([]byte, 0)
@@ -302,7 +579,7 @@ func PrefetchBlock(blockNumber *big.Int, startBlock bool, hasher types.TrieHashe
 		saveinput = append(saveinput, inputs[i].Bytes()[:]...)
 	}
 	inputhash = crypto.Keccak256Hash(saveinput)
-	preimages[inputhash] = saveinput
+	putPreimage(inputhash, saveinput)
 	ioutil.WriteFile(fmt.Sprintf("%s/input", root), inputhash.Bytes(), 0644)
 	//ioutil.WriteFile(fmt.Sprintf("%s/input", root), saveinput, 0644)
 
@@ -332,9 +609,20 @@ func PrefetchBlock(blockNumber *big.Int, startBlock bool, hasher types.TrieHashe
 	prefetchUncles(blockHeader.Hash(), blockHeader.UncleHash, hasher)
 }
 
+// getChainID asks the node for the chain ID of the network it follows.
+func getChainID() uint64 {
+	r := jsonreq{Jsonrpc: "2.0", Method: "eth_chainId", Id: 1}
+	r.Params = make([]interface{}, 0)
+	jsonData, err := json.Marshal(r)
+	check(err)
+	jr := jsonrespi{}
+	check(json.NewDecoder(getAPI(jsonData)).Decode(&jr))
+	return uint64(jr.Result)
+}
+
 func getProofAccount(blockNumber *big.Int, addr common.Address, skey common.Hash, storage bool) []string {
 	addrHash := crypto.Keccak256Hash(addr[:])
-	unhashMap[addrHash] = addr
+	setUnhash(addrHash, addr)
 
 	r := jsonreq{Jsonrpc: "2.0", Method: "eth_getProof", Id: 1}
 	r.Params = make([]interface{}, 3)
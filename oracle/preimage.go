@@ -8,6 +8,7 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
+	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
@@ -16,6 +17,30 @@ import (
 var preimages = make(map[common.Hash][]byte)
 var root = "/tmp/cannon"
 
+// lock guards the preimages and the fetch bookkeeping, the trie prefetcher
+// fetches and resolves nodes in the background while the block executes.
+var lock sync.Mutex
+
+// inMemory is set when all preimages are put in memory up front, nothing is
+// fetched from the node or written to disk then. Guarded by lock.
+var inMemory bool
+
+// UseMemory drops all preimages and switches the oracle to serve only the ones
+// put with PreimageKeyValueWriter afterwards, for running without a node.
+func UseMemory() {
+	lock.Lock()
+	defer lock.Unlock()
+	inMemory = true
+	preimages = make(map[common.Hash][]byte)
+}
+
+// memoryOnly reports whether the oracle was switched to UseMemory.
+func memoryOnly() bool {
+	lock.Lock()
+	defer lock.Unlock()
+	return inMemory
+}
+
 func SetRoot(newRoot string) {
 	root = newRoot
 	err := os.MkdirAll(root, os.ModePerm)
@@ -25,7 +50,12 @@ func SetRoot(newRoot string) {
 }
 
 func Preimage(hash common.Hash) []byte {
+	lock.Lock()
+	defer lock.Unlock()
 	val, ok := preimages[hash]
+	if inMemory {
+		return val
+	}
 	key := fmt.Sprintf("%s/%s", root, hash)
 	// We write the preimage even if its value is nil (will result in an empty file).
 	// This can happen if the hash represents a full node that is the child of another full node
@@ -39,8 +69,19 @@ func Preimage(hash common.Hash) []byte {
 	return val
 }
 
-func Preimages() map[common.Hash][]byte {
-	return preimages
+// putPreimage makes val available as the preimage of hash.
+func putPreimage(hash common.Hash, val []byte) {
+	lock.Lock()
+	defer lock.Unlock()
+	preimages[hash] = val
+}
+
+// readPreimage returns the preimage of hash without recording it.
+func readPreimage(hash common.Hash) ([]byte, bool) {
+	lock.Lock()
+	defer lock.Unlock()
+	val, ok := preimages[hash]
+	return val, ok
 }
 
 // PreimageKeyValueWriter wraps the Put method of a backing data store.
@@ -52,7 +93,7 @@ func (kw PreimageKeyValueWriter) Put(key []byte, value []byte) error {
 	if hash != common.BytesToHash(key) {
 		panic("bad preimage value write")
 	}
-	preimages[hash] = common.CopyBytes(value)
+	putPreimage(hash, common.CopyBytes(value))
 	return nil
 }
 
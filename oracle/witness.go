@@ -0,0 +1,42 @@
+package oracle
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Witness lists the accounts and storage slots a block transition reads, so
+// they can be prefetched up front instead of one by one during execution.
+type Witness map[common.Address]map[common.Hash]struct{}
+
+// Add records an account and optionally some of its storage slots.
+func (w Witness) Add(addr common.Address, slots ...common.Hash) {
+	if w[addr] == nil {
+		w[addr] = make(map[common.Hash]struct{})
+	}
+	for _, slot := range slots {
+		w[addr][slot] = struct{}{}
+	}
+}
+
+// Slots returns the storage slots recorded for an account in ascending order.
+func (w Witness) Slots(addr common.Address) []common.Hash {
+	slots := make([]common.Hash, 0, len(w[addr]))
+	for slot := range w[addr] {
+		slots = append(slots, slot)
+	}
+	sort.Slice(slots, func(i, j int) bool { return bytes.Compare(slots[i][:], slots[j][:]) < 0 })
+	return slots
+}
+
+// Addresses returns the recorded accounts in ascending order.
+func (w Witness) Addresses() []common.Address {
+	addrs := make([]common.Address, 0, len(w))
+	for addr := range w {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return bytes.Compare(addrs[i][:], addrs[j][:]) < 0 })
+	return addrs
+}
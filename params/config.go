@@ -55,25 +55,30 @@ var CheckpointOracles = map[common.Hash]*CheckpointOracleConfig{
 }
 
 var (
+	// MainnetTerminalTotalDifficulty is the total difficulty that triggers the
+	// transition of the main network to proof-of-stake.
+	MainnetTerminalTotalDifficulty, _ = new(big.Int).SetString("58_750_000_000_000_000_000_000", 0)
+
 	// MainnetChainConfig is the chain parameters to run a node on the main network.
 	MainnetChainConfig = &ChainConfig{
-		ChainID:             big.NewInt(1),
-		HomesteadBlock:      big.NewInt(1_150_000),
-		DAOForkBlock:        big.NewInt(1_920_000),
-		DAOForkSupport:      true,
-		EIP150Block:         big.NewInt(2_463_000),
-		EIP150Hash:          common.HexToHash("0x2086799aeebeae135c246c65021c82b4e15a2c451340993aacfd2751886514f0"),
-		EIP155Block:         big.NewInt(2_675_000),
-		EIP158Block:         big.NewInt(2_675_000),
-		ByzantiumBlock:      big.NewInt(4_370_000),
-		ConstantinopleBlock: big.NewInt(7_280_000),
-		PetersburgBlock:     big.NewInt(7_280_000),
-		IstanbulBlock:       big.NewInt(9_069_000),
-		MuirGlacierBlock:    big.NewInt(9_200_000),
-		BerlinBlock:         big.NewInt(12_244_000),
-		LondonBlock:         big.NewInt(12_965_000),
-		ArrowGlacierBlock:   big.NewInt(13_773_000),
-		Ethash:              new(EthashConfig),
+		ChainID:                 big.NewInt(1),
+		HomesteadBlock:          big.NewInt(1_150_000),
+		DAOForkBlock:            big.NewInt(1_920_000),
+		DAOForkSupport:          true,
+		EIP150Block:             big.NewInt(2_463_000),
+		EIP150Hash:              common.HexToHash("0x2086799aeebeae135c246c65021c82b4e15a2c451340993aacfd2751886514f0"),
+		EIP155Block:             big.NewInt(2_675_000),
+		EIP158Block:             big.NewInt(2_675_000),
+		ByzantiumBlock:          big.NewInt(4_370_000),
+		ConstantinopleBlock:     big.NewInt(7_280_000),
+		PetersburgBlock:         big.NewInt(7_280_000),
+		IstanbulBlock:           big.NewInt(9_069_000),
+		MuirGlacierBlock:        big.NewInt(9_200_000),
+		BerlinBlock:             big.NewInt(12_244_000),
+		LondonBlock:             big.NewInt(12_965_000),
+		ArrowGlacierBlock:       big.NewInt(13_773_000),
+		TerminalTotalDifficulty: MainnetTerminalTotalDifficulty,
+		Ethash:                  new(EthashConfig),
 	}
 
 	// MainnetTrustedCheckpoint contains the light client trusted checkpoint for the main network.
@@ -99,22 +104,23 @@ var (
 
 	// RopstenChainConfig contains the chain parameters to run a node on the Ropsten test network.
 	RopstenChainConfig = &ChainConfig{
-		ChainID:             big.NewInt(3),
-		HomesteadBlock:      big.NewInt(0),
-		DAOForkBlock:        nil,
-		DAOForkSupport:      true,
-		EIP150Block:         big.NewInt(0),
-		EIP150Hash:          common.HexToHash("0x41941023680923e0fe4d74a34bdac8141f2540e3ae90623718e47d66d1ca4a2d"),
-		EIP155Block:         big.NewInt(10),
-		EIP158Block:         big.NewInt(10),
-		ByzantiumBlock:      big.NewInt(1_700_000),
-		ConstantinopleBlock: big.NewInt(4_230_000),
-		PetersburgBlock:     big.NewInt(4_939_394),
-		IstanbulBlock:       big.NewInt(6_485_846),
-		MuirGlacierBlock:    big.NewInt(7_117_117),
-		BerlinBlock:         big.NewInt(9_812_189),
-		LondonBlock:         big.NewInt(10_499_401),
-		Ethash:              new(EthashConfig),
+		ChainID:                 big.NewInt(3),
+		HomesteadBlock:          big.NewInt(0),
+		DAOForkBlock:            nil,
+		DAOForkSupport:          true,
+		EIP150Block:             big.NewInt(0),
+		EIP150Hash:              common.HexToHash("0x41941023680923e0fe4d74a34bdac8141f2540e3ae90623718e47d66d1ca4a2d"),
+		EIP155Block:             big.NewInt(10),
+		EIP158Block:             big.NewInt(10),
+		ByzantiumBlock:          big.NewInt(1_700_000),
+		ConstantinopleBlock:     big.NewInt(4_230_000),
+		PetersburgBlock:         big.NewInt(4_939_394),
+		IstanbulBlock:           big.NewInt(6_485_846),
+		MuirGlacierBlock:        big.NewInt(7_117_117),
+		BerlinBlock:             big.NewInt(9_812_189),
+		LondonBlock:             big.NewInt(10_499_401),
+		TerminalTotalDifficulty: big.NewInt(50_000_000_000_000_000),
+		Ethash:                  new(EthashConfig),
 	}
 
 	// RopstenTrustedCheckpoint contains the light client trusted checkpoint for the Ropsten test network.
@@ -140,21 +146,22 @@ var (
 
 	// SepoliaChainConfig contains the chain parameters to run a node on the Sepolia test network.
 	SepoliaChainConfig = &ChainConfig{
-		ChainID:             big.NewInt(11155111),
-		HomesteadBlock:      big.NewInt(0),
-		DAOForkBlock:        nil,
-		DAOForkSupport:      true,
-		EIP150Block:         big.NewInt(0),
-		EIP155Block:         big.NewInt(0),
-		EIP158Block:         big.NewInt(0),
-		ByzantiumBlock:      big.NewInt(0),
-		ConstantinopleBlock: big.NewInt(0),
-		PetersburgBlock:     big.NewInt(0),
-		IstanbulBlock:       big.NewInt(0),
-		MuirGlacierBlock:    big.NewInt(0),
-		BerlinBlock:         big.NewInt(0),
-		LondonBlock:         big.NewInt(0),
-		Ethash:              new(EthashConfig),
+		ChainID:                 big.NewInt(11155111),
+		HomesteadBlock:          big.NewInt(0),
+		DAOForkBlock:            nil,
+		DAOForkSupport:          true,
+		EIP150Block:             big.NewInt(0),
+		EIP155Block:             big.NewInt(0),
+		EIP158Block:             big.NewInt(0),
+		ByzantiumBlock:          big.NewInt(0),
+		ConstantinopleBlock:     big.NewInt(0),
+		PetersburgBlock:         big.NewInt(0),
+		IstanbulBlock:           big.NewInt(0),
+		MuirGlacierBlock:        big.NewInt(0),
+		BerlinBlock:             big.NewInt(0),
+		LondonBlock:             big.NewInt(0),
+		TerminalTotalDifficulty: big.NewInt(17_000_000_000_000_000),
+		Ethash:                  new(EthashConfig),
 	}
 
 	// SepoliaTrustedCheckpoint contains the light client trusted checkpoint for the Sepolia test network.
@@ -211,21 +218,22 @@ var (
 
 	// GoerliChainConfig contains the chain parameters to run a node on the Görli test network.
 	GoerliChainConfig = &ChainConfig{
-		ChainID:             big.NewInt(5),
-		HomesteadBlock:      big.NewInt(0),
-		DAOForkBlock:        nil,
-		DAOForkSupport:      true,
-		EIP150Block:         big.NewInt(0),
-		EIP155Block:         big.NewInt(0),
-		EIP158Block:         big.NewInt(0),
-		ByzantiumBlock:      big.NewInt(0),
-		ConstantinopleBlock: big.NewInt(0),
-		PetersburgBlock:     big.NewInt(0),
-		IstanbulBlock:       big.NewInt(1_561_651),
-		MuirGlacierBlock:    nil,
-		BerlinBlock:         big.NewInt(4_460_644),
-		LondonBlock:         big.NewInt(5_062_605),
-		ArrowGlacierBlock:   nil,
+		ChainID:                 big.NewInt(5),
+		HomesteadBlock:          big.NewInt(0),
+		DAOForkBlock:            nil,
+		DAOForkSupport:          true,
+		EIP150Block:             big.NewInt(0),
+		EIP155Block:             big.NewInt(0),
+		EIP158Block:             big.NewInt(0),
+		ByzantiumBlock:          big.NewInt(0),
+		ConstantinopleBlock:     big.NewInt(0),
+		PetersburgBlock:         big.NewInt(0),
+		IstanbulBlock:           big.NewInt(1_561_651),
+		MuirGlacierBlock:        nil,
+		BerlinBlock:             big.NewInt(4_460_644),
+		LondonBlock:             big.NewInt(5_062_605),
+		ArrowGlacierBlock:       nil,
+		TerminalTotalDifficulty: big.NewInt(10_790_000),
 		Clique: &CliqueConfig{
 			Period: 15,
 			Epoch:  30000,
@@ -258,16 +266,16 @@ var (
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, new(EthashConfig), nil}
+	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, new(EthashConfig), nil, nil, nil}
 
 	// AllCliqueProtocolChanges contains every protocol change (EIPs) introduced
 	// and accepted by the Ethereum core developers into the Clique consensus.
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, &CliqueConfig{Period: 0, Epoch: 30000}}
+	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, &CliqueConfig{Period: 0, Epoch: 30000}, nil, nil}
 
-	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, new(EthashConfig), nil}
+	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, new(EthashConfig), nil, nil, nil}
 	TestRules       = TestChainConfig.Rules(new(big.Int), false)
 )
 
@@ -319,6 +327,25 @@ type CheckpointOracleConfig struct {
 	Threshold uint64           `json:"threshold"`
 }
 
+// ChainConfigs associates the chain ID of each known network with its chain
+// configuration.
+var ChainConfigs = map[uint64]*ChainConfig{
+	1:        MainnetChainConfig,
+	3:        RopstenChainConfig,
+	4:        RinkebyChainConfig,
+	5:        GoerliChainConfig,
+	11155111: SepoliaChainConfig,
+}
+
+// ChainConfigByID returns the configuration of the known network with the
+// given chain ID, nil if there is none.
+func ChainConfigByID(chainID *big.Int) *ChainConfig {
+	if !chainID.IsUint64() {
+		return nil
+	}
+	return ChainConfigs[chainID.Uint64()]
+}
+
 // ChainConfig is the core config which determines the blockchain settings.
 //
 // ChainConfig is stored in the database on a per block basis. This means
@@ -356,6 +383,13 @@ type ChainConfig struct {
 	// Various consensus engines
 	Ethash *EthashConfig `json:"ethash,omitempty"`
 	Clique *CliqueConfig `json:"clique,omitempty"`
+
+	// EIP1559 overrides the base fee parameters (nil = mainnet values)
+	EIP1559 *EIP1559Config `json:"eip1559,omitempty"`
+
+	// PrecompileUpgrades enables additional precompiled contract sets on top
+	// of the ones of the active fork (nil = no extra precompiles)
+	PrecompileUpgrades []PrecompileUpgrade `json:"precompileUpgrades,omitempty"`
 }
 
 // EthashConfig is the consensus engine configs for proof-of-work based sealing.
@@ -377,6 +411,37 @@ func (c *CliqueConfig) String() string {
 	return "clique"
 }
 
+// EIP1559Config holds the base fee market parameters for chains that don't use
+// the mainnet values, such as L2s. Zero values fall back to the protocol defaults.
+type EIP1559Config struct {
+	ElasticityMultiplier     uint64 `json:"elasticityMultiplier,omitempty"`     // Bounds the maximum gas limit an EIP-1559 block may have
+	BaseFeeChangeDenominator uint64 `json:"baseFeeChangeDenominator,omitempty"` // Bounds the amount the base fee can change between blocks
+
+	// Forks lists parameter changes scheduled at later blocks, in ascending order.
+	Forks []EIP1559Fork `json:"forks,omitempty"`
+}
+
+// EIP1559Fork changes the base fee parameters starting at Block. Zero values
+// leave the previous parameter in place.
+type EIP1559Fork struct {
+	Block                    *big.Int `json:"block"`
+	ElasticityMultiplier     uint64   `json:"elasticityMultiplier,omitempty"`
+	BaseFeeChangeDenominator uint64   `json:"baseFeeChangeDenominator,omitempty"`
+}
+
+// String implements the stringer interface.
+func (c *EIP1559Config) String() string {
+	return fmt.Sprintf("{Elasticity: %d Denominator: %d Forks: %d}", c.ElasticityMultiplier, c.BaseFeeChangeDenominator, len(c.Forks))
+}
+
+// PrecompileUpgrade enables named sets of precompiled contracts starting at
+// Block. The names refer to the sets known to core/vm, e.g. "eip2537" for the
+// BLS12-381 contracts.
+type PrecompileUpgrade struct {
+	Block  *big.Int `json:"block"`
+	Enable []string `json:"enable"`
+}
+
 // String implements the fmt.Stringer interface.
 func (c *ChainConfig) String() string {
 	var engine interface{}
@@ -388,7 +453,7 @@ func (c *ChainConfig) String() string {
 	default:
 		engine = "unknown"
 	}
-	return fmt.Sprintf("{ChainID: %v Homestead: %v DAO: %v DAOSupport: %v EIP150: %v EIP155: %v EIP158: %v Byzantium: %v Constantinople: %v Petersburg: %v Istanbul: %v, Muir Glacier: %v, Berlin: %v, London: %v, Arrow Glacier: %v, MergeFork: %v, Terminal TD: %v, Engine: %v}",
+	return fmt.Sprintf("{ChainID: %v Homestead: %v DAO: %v DAOSupport: %v EIP150: %v EIP155: %v EIP158: %v Byzantium: %v Constantinople: %v Petersburg: %v Istanbul: %v, Muir Glacier: %v, Berlin: %v, London: %v, Arrow Glacier: %v, MergeFork: %v, Terminal TD: %v, EIP1559: %v, Engine: %v}",
 		c.ChainID,
 		c.HomesteadBlock,
 		c.DAOForkBlock,
@@ -406,6 +471,7 @@ func (c *ChainConfig) String() string {
 		c.ArrowGlacierBlock,
 		c.MergeForkBlock,
 		c.TerminalTotalDifficulty,
+		c.EIP1559,
 		engine,
 	)
 }
@@ -484,6 +550,54 @@ func (c *ChainConfig) IsMerge(num *big.Int) bool {
 	return isForked(c.MergeForkBlock, num)
 }
 
+// ElasticityMultiplier returns the EIP-1559 elasticity multiplier in effect at
+// block num.
+func (c *ChainConfig) ElasticityMultiplier(num *big.Int) uint64 {
+	elasticity := uint64(ElasticityMultiplier)
+	if c.EIP1559 == nil {
+		return elasticity
+	}
+	if c.EIP1559.ElasticityMultiplier != 0 {
+		elasticity = c.EIP1559.ElasticityMultiplier
+	}
+	for _, f := range c.EIP1559.Forks {
+		if isForked(f.Block, num) && f.ElasticityMultiplier != 0 {
+			elasticity = f.ElasticityMultiplier
+		}
+	}
+	return elasticity
+}
+
+// BaseFeeChangeDenominator returns the EIP-1559 base fee change denominator in
+// effect at block num.
+func (c *ChainConfig) BaseFeeChangeDenominator(num *big.Int) uint64 {
+	denominator := uint64(BaseFeeChangeDenominator)
+	if c.EIP1559 == nil {
+		return denominator
+	}
+	if c.EIP1559.BaseFeeChangeDenominator != 0 {
+		denominator = c.EIP1559.BaseFeeChangeDenominator
+	}
+	for _, f := range c.EIP1559.Forks {
+		if isForked(f.Block, num) && f.BaseFeeChangeDenominator != 0 {
+			denominator = f.BaseFeeChangeDenominator
+		}
+	}
+	return denominator
+}
+
+// ActivePrecompileSets returns the names of the extra precompiled contract
+// sets enabled at block num, in activation order.
+func (c *ChainConfig) ActivePrecompileSets(num *big.Int) []string {
+	var sets []string
+	for _, u := range c.PrecompileUpgrades {
+		if isForked(u.Block, num) {
+			sets = append(sets, u.Enable...)
+		}
+	}
+	return sets
+}
+
 // IsTerminalPoWBlock returns whether the given block is the last block of PoW stage.
 func (c *ChainConfig) IsTerminalPoWBlock(parentTotalDiff *big.Int, totalDiff *big.Int) bool {
 	if c.TerminalTotalDifficulty == nil {
@@ -553,6 +667,30 @@ func (c *ChainConfig) CheckConfigForkOrder() error {
 			lastFork = cur
 		}
 	}
+	var lastUpgrade *big.Int
+	for i, u := range c.PrecompileUpgrades {
+		if u.Block == nil {
+			return fmt.Errorf("precompile upgrade %d has no activation block", i)
+		}
+		if lastUpgrade != nil && lastUpgrade.Cmp(u.Block) > 0 {
+			return fmt.Errorf("unsupported precompile upgrade ordering: upgrade %d at %v, but upgrade %d at %v",
+				i-1, lastUpgrade, i, u.Block)
+		}
+		lastUpgrade = u.Block
+	}
+	if c.EIP1559 != nil {
+		var last *big.Int
+		for i, f := range c.EIP1559.Forks {
+			if f.Block == nil {
+				return fmt.Errorf("eip1559 fork %d has no activation block", i)
+			}
+			if last != nil && last.Cmp(f.Block) > 0 {
+				return fmt.Errorf("unsupported eip1559 fork ordering: fork %d at %v, but fork %d at %v",
+					i-1, last, i, f.Block)
+			}
+			last = f.Block
+		}
+	}
 	return nil
 }
 
@@ -609,9 +747,36 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int) *Confi
 	if isForkIncompatible(c.MergeForkBlock, newcfg.MergeForkBlock, head) {
 		return newCompatError("Merge Start fork block", c.MergeForkBlock, newcfg.MergeForkBlock)
 	}
+	for i := 0; i < len(c.PrecompileUpgrades) || i < len(newcfg.PrecompileUpgrades); i++ {
+		var stored, upgrade PrecompileUpgrade
+		if i < len(c.PrecompileUpgrades) {
+			stored = c.PrecompileUpgrades[i]
+		}
+		if i < len(newcfg.PrecompileUpgrades) {
+			upgrade = newcfg.PrecompileUpgrades[i]
+		}
+		if isForkIncompatible(stored.Block, upgrade.Block, head) {
+			return newCompatError("precompile upgrade block", stored.Block, upgrade.Block)
+		}
+		if isForked(stored.Block, head) && !stringsEqual(stored.Enable, upgrade.Enable) {
+			return newCompatError("precompile upgrade sets", stored.Block, upgrade.Block)
+		}
+	}
 	return nil
 }
 
+func stringsEqual(x, y []string) bool {
+	if len(x) != len(y) {
+		return false
+	}
+	for i := range x {
+		if x[i] != y[i] {
+			return false
+		}
+	}
+	return true
+}
+
 // isForkIncompatible returns true if a fork scheduled at s1 cannot be rescheduled to
 // block s2 because head is already past the fork.
 func isForkIncompatible(s1, s2, head *big.Int) bool {
@@ -678,6 +843,10 @@ type Rules struct {
 	IsByzantium, IsConstantinople, IsPetersburg, IsIstanbul bool
 	IsBerlin, IsLondon                                      bool
 	IsMerge                                                 bool
+
+	// Precompiles lists the extra precompiled contract sets enabled on top of
+	// the fork defaults.
+	Precompiles []string
 }
 
 // Rules ensures c's ChainID is not nil.
@@ -699,5 +868,6 @@ func (c *ChainConfig) Rules(num *big.Int, isMerge bool) Rules {
 		IsBerlin:         c.IsBerlin(num),
 		IsLondon:         c.IsLondon(num),
 		IsMerge:          isMerge,
+		Precompiles:      c.ActivePrecompileSets(num),
 	}
 }
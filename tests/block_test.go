@@ -0,0 +1,44 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// TestBlockchain runs the BlockchainTests fixtures of the networks this tree
+// knows about.
+func TestBlockchain(t *testing.T) {
+	walkFixtures(t, fixturesDir(t, "BlockchainTests"), func(t *testing.T, data []byte) {
+		var tests map[string]BlockTest
+		if err := json.Unmarshal(data, &tests); err != nil {
+			t.Fatal(err)
+		}
+		for name, test := range tests {
+			test := test
+			t.Run(name, func(t *testing.T) {
+				if _, err := GetChainConfig(test.json.Network); err != nil {
+					t.Skip(err)
+				}
+				if err := test.Run(); err != nil {
+					t.Fatal(err)
+				}
+			})
+		}
+	})
+}
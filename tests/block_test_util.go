@@ -0,0 +1,250 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/oracle"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/transition"
+)
+
+// A BlockTest checks handling of entire blocks. Every block goes through the
+// transition package the way main does, on top of the state its parent left
+// in the oracle.
+type BlockTest struct {
+	json btJSON
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface.
+func (t *BlockTest) UnmarshalJSON(in []byte) error {
+	return json.Unmarshal(in, &t.json)
+}
+
+type btJSON struct {
+	Blocks        []btBlock             `json:"blocks"`
+	Genesis       btHeader              `json:"genesisBlockHeader"`
+	Pre           GenesisAlloc          `json:"pre"`
+	Post          GenesisAlloc          `json:"postState"`
+	PostStateHash *common.Hash          `json:"postStateHash"`
+	BestBlock     common.UnprefixedHash `json:"lastblockhash"`
+	Network       string                `json:"network"`
+	SealEngine    string                `json:"sealEngine"`
+}
+
+type btBlock struct {
+	ExpectException string `json:"expectException"`
+	Rlp             string `json:"rlp"`
+}
+
+type btHeader struct {
+	Bloom            types.Bloom           `json:"bloom"`
+	Coinbase         common.Address        `json:"coinbase"`
+	MixHash          common.Hash           `json:"mixHash"`
+	Nonce            types.BlockNonce      `json:"nonce"`
+	Number           *math.HexOrDecimal256 `json:"number"`
+	Hash             common.Hash           `json:"hash"`
+	ParentHash       common.Hash           `json:"parentHash"`
+	ReceiptTrie      common.Hash           `json:"receiptTrie"`
+	StateRoot        common.Hash           `json:"stateRoot"`
+	TransactionsTrie common.Hash           `json:"transactionsTrie"`
+	UncleHash        common.Hash           `json:"uncleHash"`
+	ExtraData        hexutil.Bytes         `json:"extraData"`
+	Difficulty       *math.HexOrDecimal256 `json:"difficulty"`
+	GasLimit         math.HexOrDecimal64   `json:"gasLimit"`
+	GasUsed          math.HexOrDecimal64   `json:"gasUsed"`
+	Timestamp        math.HexOrDecimal64   `json:"timestamp"`
+	BaseFeePerGas    *math.HexOrDecimal256 `json:"baseFeePerGas"`
+}
+
+func (h *btHeader) toHeader() *types.Header {
+	return &types.Header{
+		ParentHash:  h.ParentHash,
+		UncleHash:   h.UncleHash,
+		Coinbase:    h.Coinbase,
+		Root:        h.StateRoot,
+		TxHash:      h.TransactionsTrie,
+		ReceiptHash: h.ReceiptTrie,
+		Bloom:       h.Bloom,
+		Difficulty:  (*big.Int)(h.Difficulty),
+		Number:      (*big.Int)(h.Number),
+		GasLimit:    uint64(h.GasLimit),
+		GasUsed:     uint64(h.GasUsed),
+		Time:        uint64(h.Timestamp),
+		Extra:       h.ExtraData,
+		MixDigest:   h.MixHash,
+		Nonce:       h.Nonce,
+		BaseFee:     (*big.Int)(h.BaseFeePerGas),
+	}
+}
+
+// sharedEthash keeps the verification caches across tests.
+var sharedEthash = new(ethash.Ethash)
+
+// Run imports the blocks of the test one by one. Blocks the fixture expects to
+// fail must be rejected, the others must be accepted. The post state is
+// checked in the state of the best block.
+func (t *BlockTest) Run() error {
+	config, err := GetChainConfig(t.json.Network)
+	if err != nil {
+		return err
+	}
+	var engine consensus.Engine
+	if t.json.SealEngine == "NoProof" {
+		engine = beacon.New(ethash.NewFaker())
+	} else {
+		engine = beacon.New(sharedEthash)
+	}
+
+	// The chain only lives in the oracle, the prefetching is turned off
+	oracle.UseMemory()
+	genesis := t.json.Genesis.toHeader()
+	if root := MakePreState(t.json.Pre); root != genesis.Root {
+		return fmt.Errorf("genesis state root mismatch: have %x, want %x", root, genesis.Root)
+	}
+	if genesis.Hash() != t.json.Genesis.Hash {
+		return fmt.Errorf("genesis block hash mismatch: have %x, want %x", genesis.Hash(), t.json.Genesis.Hash)
+	}
+	putHeader(genesis)
+
+	tds := map[common.Hash]*big.Int{genesis.Hash(): genesis.Difficulty}
+	best := genesis
+	for i, b := range t.json.Blocks {
+		header, err := t.insertBlock(config, engine, b, tds)
+		if err != nil {
+			if b.ExpectException == "" {
+				return fmt.Errorf("block #%d insertion failed: %v", i, err)
+			}
+			continue
+		}
+		if b.ExpectException != "" {
+			return fmt.Errorf("block #%d insertion should have failed with %q", i, b.ExpectException)
+		}
+		// proof-of-stake blocks are all imported as the new head
+		if header.Difficulty.Sign() == 0 || tds[header.Hash()].Cmp(tds[best.Hash()]) > 0 {
+			best = header
+		}
+	}
+	if best.Hash() != common.Hash(t.json.BestBlock) {
+		return fmt.Errorf("last block hash mismatch: have %x, want %x", best.Hash(), t.json.BestBlock)
+	}
+	return t.validatePostState(best)
+}
+
+// insertBlock processes the block b on top of its parent and commits its state
+// into the oracle. It returns the header of the block.
+func (t *BlockTest) insertBlock(config *params.ChainConfig, engine consensus.Engine, b btBlock, tds map[common.Hash]*big.Int) (*types.Header, error) {
+	var block types.Block
+	if err := rlp.DecodeBytes(common.FromHex(b.Rlp), &block); err != nil {
+		return nil, fmt.Errorf("bad block rlp: %v", err)
+	}
+	header := block.Header()
+	parentTd, ok := tds[header.ParentHash]
+	if !ok {
+		return nil, consensus.ErrUnknownAncestor
+	}
+	// Process checks the header against the consensus rules, the fields that
+	// result from processing are compared here
+	inputs := transition.PutBlock(&block, parentTd)
+	result, err := transition.Process(context.Background(), config, engine, inputs, vm.Config{})
+	if err != nil {
+		return nil, err
+	}
+	if result.GasUsed != header.GasUsed {
+		return nil, fmt.Errorf("invalid gas used: have %d, want %d", result.GasUsed, header.GasUsed)
+	}
+	if bloom := types.CreateBloom(result.Receipts); bloom != header.Bloom {
+		return nil, fmt.Errorf("invalid bloom: have %x, want %x", bloom, header.Bloom)
+	}
+	if result.ReceiptHash != header.ReceiptHash {
+		return nil, fmt.Errorf("invalid receipt root hash: have %x, want %x", result.ReceiptHash, header.ReceiptHash)
+	}
+	if result.Root != header.Root {
+		return nil, fmt.Errorf("invalid merkle root: have %x, want %x", result.Root, header.Root)
+	}
+	// the next blocks read this state from the oracle
+	if _, err := result.StateDB.Commit(config.IsEIP158(header.Number)); err != nil {
+		return nil, err
+	}
+	tds[header.Hash()] = new(big.Int).Add(parentTd, header.Difficulty)
+	return header, nil
+}
+
+// putHeader puts the RLP of header into the oracle.
+func putHeader(header *types.Header) {
+	enc, err := rlp.EncodeToBytes(header)
+	if err != nil {
+		panic(err)
+	}
+	oracle.PreimageKeyValueWriter{}.Put(crypto.Keccak256(enc), enc)
+}
+
+// validatePostState compares the state of header with the post state of the
+// test, or its root if the fixture only has the hash.
+func (t *BlockTest) validatePostState(header *types.Header) error {
+	if t.json.Post == nil && t.json.PostStateHash != nil {
+		if header.Root != *t.json.PostStateHash {
+			return fmt.Errorf("post state root mismatch: have %x, want %x", header.Root, *t.json.PostStateHash)
+		}
+		return nil
+	}
+	statedb, err := state.New(header.Root, state.NewDatabase(*header), nil)
+	if err != nil {
+		return err
+	}
+	for addr, acct := range t.json.Post {
+		// address is indirectly verified by the other fields, as it's the db key
+		code := statedb.GetCode(addr)
+		balance := statedb.GetBalance(addr)
+		nonce := statedb.GetNonce(addr)
+		if !bytes.Equal(code, acct.Code) {
+			return fmt.Errorf("account code mismatch for addr: %s want: %x have: %x", addr, []byte(acct.Code), code)
+		}
+		want := new(big.Int)
+		if acct.Balance != nil {
+			want = (*big.Int)(acct.Balance)
+		}
+		if balance.Cmp(want) != 0 {
+			return fmt.Errorf("account balance mismatch for addr: %s, want: %d, have: %d", addr, want, balance)
+		}
+		if nonce != uint64(acct.Nonce) {
+			return fmt.Errorf("account nonce mismatch for addr: %s want: %d have: %d", addr, acct.Nonce, nonce)
+		}
+		for k, v := range acct.Storage {
+			if have := statedb.GetState(addr, common.Hash(k)); have != common.Hash(v) {
+				return fmt.Errorf("storage mismatch for addr: %s, slot: %x, want: %x, have: %x", addr, k, v, have)
+			}
+		}
+	}
+	return statedb.Error()
+}
@@ -0,0 +1,66 @@
+// Copyright 2017 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tests
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fixturesDir returns the directory of the fixtures of a kind, e.g.
+// GeneralStateTests, in the checkout of github.com/ethereum/tests named by
+// ETHEREUM_TESTS, or in testdata. The test is skipped if there is none.
+func fixturesDir(t *testing.T, kind string) string {
+	base := os.Getenv("ETHEREUM_TESTS")
+	if base == "" {
+		base = "testdata"
+	}
+	dir := filepath.Join(base, kind)
+	if _, err := os.Stat(dir); err != nil {
+		t.Skipf("no %s fixtures in %s", kind, base)
+	}
+	return dir
+}
+
+// walkFixtures runs runTest in a subtest for every JSON file below dir, named
+// by its path relative to dir. The tests share the oracle so they don't run in
+// parallel.
+func walkFixtures(t *testing.T, dir string, runTest func(t *testing.T, data []byte)) {
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() || filepath.Ext(path) != ".json" {
+			return nil
+		}
+		name := filepath.ToSlash(strings.TrimPrefix(path, dir+string(filepath.Separator)))
+		t.Run(name, func(t *testing.T) {
+			data, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			runTest(t, data)
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// TestState runs the GeneralStateTests fixtures, every subtest of a fork this
+// tree knows about.
+func TestState(t *testing.T) {
+	walkFixtures(t, fixturesDir(t, "GeneralStateTests"), func(t *testing.T, data []byte) {
+		var tests map[string]StateTest
+		if err := json.Unmarshal(data, &tests); err != nil {
+			t.Fatal(err)
+		}
+		for name, test := range tests {
+			test := test
+			for _, subtest := range test.Subtests() {
+				subtest := subtest
+				key := fmt.Sprintf("%s/%s/%d", name, subtest.Fork, subtest.Index)
+				t.Run(key, func(t *testing.T) {
+					if _, err := GetChainConfig(subtest.Fork); err != nil {
+						t.Skip(err)
+					}
+					if _, err := test.Run(subtest, vm.Config{}); err != nil {
+						t.Fatal(err)
+					}
+				})
+			}
+		}
+	})
+}
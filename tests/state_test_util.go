@@ -0,0 +1,434 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tests
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/oracle"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+	"golang.org/x/crypto/sha3"
+)
+
+// StateTest checks transaction processing without block context.
+// See https://github.com/ethereum/EIPs/issues/176 for the test format specification.
+type StateTest struct {
+	json stJSON
+}
+
+// StateSubtest selects a specific configuration of a General State Test.
+type StateSubtest struct {
+	Fork  string
+	Index int
+}
+
+func (t *StateTest) UnmarshalJSON(in []byte) error {
+	return json.Unmarshal(in, &t.json)
+}
+
+type stJSON struct {
+	Env  stEnv                    `json:"env"`
+	Pre  GenesisAlloc             `json:"pre"`
+	Tx   stTransaction            `json:"transaction"`
+	Out  hexutil.Bytes            `json:"out"`
+	Post map[string][]stPostState `json:"post"`
+}
+
+type stPostState struct {
+	Root            common.UnprefixedHash `json:"hash"`
+	Logs            common.UnprefixedHash `json:"logs"`
+	TxBytes         hexutil.Bytes         `json:"txbytes"`
+	ExpectException string                `json:"expectException"`
+	Indexes         struct {
+		Data  int `json:"data"`
+		Gas   int `json:"gas"`
+		Value int `json:"value"`
+	}
+}
+
+type stEnv struct {
+	Coinbase   common.Address        `json:"currentCoinbase"`
+	Difficulty *math.HexOrDecimal256 `json:"currentDifficulty"`
+	Random     *math.HexOrDecimal256 `json:"currentRandom"`
+	GasLimit   math.HexOrDecimal64   `json:"currentGasLimit"`
+	Number     math.HexOrDecimal64   `json:"currentNumber"`
+	Timestamp  math.HexOrDecimal64   `json:"currentTimestamp"`
+	BaseFee    *math.HexOrDecimal256 `json:"currentBaseFee"`
+}
+
+type stTransaction struct {
+	GasPrice             *math.HexOrDecimal256 `json:"gasPrice"`
+	MaxFeePerGas         *math.HexOrDecimal256 `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *math.HexOrDecimal256 `json:"maxPriorityFeePerGas"`
+	Nonce                math.HexOrDecimal64   `json:"nonce"`
+	To                   string                `json:"to"`
+	Data                 []string              `json:"data"`
+	AccessLists          []*types.AccessList   `json:"accessLists,omitempty"`
+	GasLimit             []math.HexOrDecimal64 `json:"gasLimit"`
+	Value                []string              `json:"value"`
+	PrivateKey           hexutil.Bytes         `json:"secretKey"`
+}
+
+// GenesisAlloc specifies the initial state of a test.
+type GenesisAlloc map[common.Address]GenesisAccount
+
+// GenesisAccount is an account in the state of a test.
+type GenesisAccount struct {
+	Code    hexutil.Bytes               `json:"code,omitempty"`
+	Storage map[storageJSON]storageJSON `json:"storage,omitempty"`
+	Balance *math.HexOrDecimal256       `json:"balance"`
+	Nonce   math.HexOrDecimal64         `json:"nonce,omitempty"`
+}
+
+// storageJSON represents a 256 bit byte array, but allows less than 256 bits when
+// unmarshaling from hex.
+type storageJSON common.Hash
+
+func (h *storageJSON) UnmarshalText(text []byte) error {
+	text = bytes.TrimPrefix(text, []byte("0x"))
+	if len(text) > 64 {
+		return fmt.Errorf("too many hex characters in storage key/value %q", text)
+	}
+	if len(text)%2 == 1 {
+		text = append([]byte{'0'}, text...)
+	}
+	offset := len(h) - len(text)/2 // pad on the left
+	if _, err := hex.Decode(h[offset:], text); err != nil {
+		return fmt.Errorf("invalid hex storage key/value %q", text)
+	}
+	return nil
+}
+
+// GetChainConfig takes a fork definition and returns a chain config.
+func GetChainConfig(forkString string) (*params.ChainConfig, error) {
+	config, ok := Forks[forkString]
+	if !ok {
+		return nil, UnsupportedForkError{forkString}
+	}
+	return config, nil
+}
+
+// Subtests returns all valid subtests of the test.
+func (t *StateTest) Subtests() []StateSubtest {
+	var sub []StateSubtest
+	for fork, pss := range t.json.Post {
+		for i := range pss {
+			sub = append(sub, StateSubtest{fork, i})
+		}
+	}
+	sort.Slice(sub, func(i, j int) bool {
+		if sub[i].Fork != sub[j].Fork {
+			return sub[i].Fork < sub[j].Fork
+		}
+		return sub[i].Index < sub[j].Index
+	})
+	return sub
+}
+
+// checkError checks if the error returned by the state transition matches any expected error.
+// A failing expectation returns a wrapped version of the original error, if any,
+// or a new error detailing the failing expectation.
+// This function does not return or modify the original error, it only evaluates and returns expectations for the error.
+func (t *StateTest) checkError(subtest StateSubtest, err error) error {
+	expectedError := t.json.Post[subtest.Fork][subtest.Index].ExpectException
+	if err == nil && expectedError == "" {
+		return nil
+	}
+	if err == nil && expectedError != "" {
+		return fmt.Errorf("expected error %q, got no error", expectedError)
+	}
+	if err != nil && expectedError == "" {
+		return fmt.Errorf("unexpected error: %w", err)
+	}
+	// The error strings of the fixtures don't match ours, any error will do
+	return nil
+}
+
+// Run executes a specific subtest and verifies the post-state and logs
+func (t *StateTest) Run(subtest StateSubtest, vmconfig vm.Config) (*state.StateDB, error) {
+	statedb, root, err := t.RunNoVerify(subtest, vmconfig)
+	if checkedErr := t.checkError(subtest, err); checkedErr != nil {
+		return statedb, checkedErr
+	}
+	// The error has been checked; if it was unexpected, it's already returned.
+	if err != nil {
+		// Here, an error exists but it was expected.
+		// We do not check the post state or logs.
+		return statedb, nil
+	}
+	post := t.json.Post[subtest.Fork][subtest.Index]
+	if root != common.Hash(post.Root) {
+		return statedb, fmt.Errorf("post state root mismatch: got %x, want %x", root, post.Root)
+	}
+	if logs := rlpHash(statedb.Logs()); logs != common.Hash(post.Logs) {
+		return statedb, fmt.Errorf("post state logs hash mismatch: got %x, want %x", logs, post.Logs)
+	}
+	return statedb, nil
+}
+
+// RunNoVerify runs a specific subtest and returns the statedb and post-state root
+func (t *StateTest) RunNoVerify(subtest StateSubtest, vmconfig vm.Config) (*state.StateDB, common.Hash, error) {
+	config, err := GetChainConfig(subtest.Fork)
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+	// The pre-state only lives in the oracle, the prefetching is turned off
+	oracle.UseMemory()
+	header := t.header(MakePreState(t.json.Pre))
+	statedb, err := state.New(header.Root, state.NewDatabase(*header), nil)
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+
+	var baseFee *big.Int
+	if config.IsLondon(new(big.Int)) {
+		baseFee = (*big.Int)(t.json.Env.BaseFee)
+		if baseFee == nil {
+			// Retesteth uses `0x10` for genesis baseFee. Therefore, it defaults to
+			// parent - 2 : 0xa as the basefee for 'this' context.
+			baseFee = big.NewInt(0x0a)
+		}
+	}
+	post := t.json.Post[subtest.Fork][subtest.Index]
+	msg, err := t.json.Tx.toMessage(post, baseFee)
+	if err != nil {
+		return nil, common.Hash{}, err
+	}
+
+	// Try to recover tx with current signer
+	if len(post.TxBytes) != 0 {
+		var ttx types.Transaction
+		err := ttx.UnmarshalBinary(post.TxBytes)
+		if err != nil {
+			return nil, common.Hash{}, err
+		}
+
+		if _, err := types.Sender(types.LatestSigner(config), &ttx); err != nil {
+			return nil, common.Hash{}, err
+		}
+	}
+
+	// Prepare the EVM.
+	txContext := core.NewEVMTxContext(msg)
+	context := core.NewEVMBlockContext(header, nil, &t.json.Env.Coinbase)
+	context.GetHash = vmTestBlockHash
+	context.BaseFee = baseFee
+	context.Random = nil
+	if config.IsLondon(new(big.Int)) {
+		if t.json.Env.Random != nil {
+			rnd := common.BigToHash((*big.Int)(t.json.Env.Random))
+			context.Random = &rnd
+		}
+		context.Difficulty = big.NewInt(0)
+	}
+	evm := vm.NewEVM(context, txContext, statedb, config, vmconfig)
+	// Execute the message.
+	snapshot := statedb.Snapshot()
+	gaspool := new(core.GasPool)
+	gaspool.AddGas(header.GasLimit)
+	_, err = core.ApplyMessage(evm, msg, gaspool)
+	if err != nil {
+		statedb.RevertToSnapshot(snapshot)
+	}
+	// Add 0-value mining reward. This only makes a difference in the cases
+	// where
+	// - the coinbase suicided, or
+	// - there are only 'bad' transactions, which aren't executed. In those cases,
+	//   the coinbase gets no txfee, so isn't created, and thus needs to be touched
+	statedb.AddBalance(context.Coinbase, new(big.Int))
+	root := statedb.IntermediateRoot(config.IsEIP158(header.Number))
+	return statedb, root, err
+}
+
+// header returns the header of the block the test runs in, on top of the
+// pre-state root.
+func (t *StateTest) header(root common.Hash) *types.Header {
+	header := &types.Header{
+		Coinbase:   t.json.Env.Coinbase,
+		Difficulty: new(big.Int),
+		Number:     new(big.Int).SetUint64(uint64(t.json.Env.Number)),
+		GasLimit:   uint64(t.json.Env.GasLimit),
+		Time:       uint64(t.json.Env.Timestamp),
+		Root:       root,
+		BaseFee:    (*big.Int)(t.json.Env.BaseFee),
+	}
+	if t.json.Env.Difficulty != nil {
+		header.Difficulty.Set((*big.Int)(t.json.Env.Difficulty))
+	}
+	return header
+}
+
+// MakePreState puts the tries and code of alloc into the oracle and returns
+// the state root.
+func MakePreState(alloc GenesisAlloc) common.Hash {
+	kw := oracle.PreimageKeyValueWriter{}
+	accounts := make(map[common.Hash][]byte)
+	for addr, a := range alloc {
+		storage := make(map[common.Hash][]byte)
+		for k, v := range a.Storage {
+			if v == (storageJSON{}) {
+				continue
+			}
+			// Encoding []byte cannot fail, ok to ignore the error.
+			enc, _ := rlp.EncodeToBytes(common.TrimLeftZeroes(v[:]))
+			storage[crypto.Keccak256Hash(k[:])] = enc
+		}
+		codeHash := crypto.Keccak256(a.Code)
+		kw.Put(codeHash, a.Code)
+
+		balance := new(big.Int)
+		if a.Balance != nil {
+			balance.Set((*big.Int)(a.Balance))
+		}
+		enc, err := rlp.EncodeToBytes(&types.StateAccount{
+			Nonce:    uint64(a.Nonce),
+			Balance:  balance,
+			Root:     commitTrie(storage),
+			CodeHash: codeHash,
+		})
+		if err != nil {
+			panic(err)
+		}
+		accounts[crypto.Keccak256Hash(addr[:])] = enc
+	}
+	return commitTrie(accounts)
+}
+
+// commitTrie writes the nodes of the trie holding the hashed keys of leaves
+// into the oracle and returns its root.
+func commitTrie(leaves map[common.Hash][]byte) common.Hash {
+	keys := make([]common.Hash, 0, len(leaves))
+	for key := range leaves {
+		keys = append(keys, key)
+	}
+	// the stack trie wants the keys in order
+	sort.Slice(keys, func(i, j int) bool {
+		return bytes.Compare(keys[i][:], keys[j][:]) < 0
+	})
+	st := trie.NewStackTrie(oracle.PreimageKeyValueWriter{})
+	for _, key := range keys {
+		if err := st.TryUpdate(key[:], leaves[key]); err != nil {
+			panic(err)
+		}
+	}
+	root, err := st.Commit()
+	if err != nil {
+		panic(err)
+	}
+	return root
+}
+
+func (tx *stTransaction) toMessage(ps stPostState, baseFee *big.Int) (types.Message, error) {
+	// Derive sender from private key if present.
+	var from common.Address
+	if len(tx.PrivateKey) > 0 {
+		key, err := crypto.ToECDSA(tx.PrivateKey)
+		if err != nil {
+			return types.Message{}, fmt.Errorf("invalid private key: %v", err)
+		}
+		from = crypto.PubkeyToAddress(key.PublicKey)
+	}
+	// Parse recipient if present.
+	var to *common.Address
+	if tx.To != "" {
+		to = new(common.Address)
+		if err := to.UnmarshalText([]byte(tx.To)); err != nil {
+			return types.Message{}, fmt.Errorf("invalid to address: %v", err)
+		}
+	}
+
+	// Get values specific to this post state.
+	if ps.Indexes.Data >= len(tx.Data) {
+		return types.Message{}, fmt.Errorf("tx data index %d out of bounds", ps.Indexes.Data)
+	}
+	if ps.Indexes.Value >= len(tx.Value) {
+		return types.Message{}, fmt.Errorf("tx value index %d out of bounds", ps.Indexes.Value)
+	}
+	if ps.Indexes.Gas >= len(tx.GasLimit) {
+		return types.Message{}, fmt.Errorf("tx gas limit index %d out of bounds", ps.Indexes.Gas)
+	}
+	dataHex := tx.Data[ps.Indexes.Data]
+	valueHex := tx.Value[ps.Indexes.Value]
+	gasLimit := tx.GasLimit[ps.Indexes.Gas]
+	// Value, Data hex encoding is messy: https://github.com/ethereum/tests/issues/203
+	value := new(big.Int)
+	if valueHex != "0x" {
+		v, ok := math.ParseBig256(valueHex)
+		if !ok {
+			return types.Message{}, fmt.Errorf("invalid tx value %q", valueHex)
+		}
+		value = v
+	}
+	data, err := hex.DecodeString(strings.TrimPrefix(dataHex, "0x"))
+	if err != nil {
+		return types.Message{}, fmt.Errorf("invalid tx data %q", dataHex)
+	}
+	var accessList types.AccessList
+	if tx.AccessLists != nil && ps.Indexes.Data < len(tx.AccessLists) && tx.AccessLists[ps.Indexes.Data] != nil {
+		accessList = *tx.AccessLists[ps.Indexes.Data]
+	}
+	// If baseFee provided, set gasPrice to effectiveGasPrice.
+	gasPrice := (*big.Int)(tx.GasPrice)
+	feeCap := (*big.Int)(tx.MaxFeePerGas)
+	tipCap := (*big.Int)(tx.MaxPriorityFeePerGas)
+	if baseFee != nil {
+		if feeCap == nil {
+			feeCap = gasPrice
+		}
+		if feeCap == nil {
+			feeCap = new(big.Int)
+		}
+		if tipCap == nil {
+			tipCap = feeCap
+		}
+		gasPrice = math.BigMin(new(big.Int).Add(tipCap, baseFee), feeCap)
+	}
+	if gasPrice == nil {
+		return types.Message{}, fmt.Errorf("no gas price provided")
+	}
+
+	msg := types.NewMessage(from, to, uint64(tx.Nonce), value, uint64(gasLimit), gasPrice,
+		feeCap, tipCap, data, accessList, false)
+	return msg, nil
+}
+
+func rlpHash(x interface{}) (h common.Hash) {
+	hw := sha3.NewLegacyKeccak256()
+	rlp.Encode(hw, x)
+	hw.Sum(h[:0])
+	return h
+}
+
+func vmTestBlockHash(n uint64) common.Hash {
+	return common.BytesToHash(crypto.Keccak256([]byte(big.NewInt(int64(n)).String())))
+}
@@ -0,0 +1,82 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers
+
+import (
+	"encoding/json"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// BlockTracer traces every transaction of a block with a fresh tracer and hands
+// the result of each transaction to a callback, in transaction order.
+type BlockTracer struct {
+	name     string
+	cfg      json.RawMessage
+	tracer   Tracer
+	index    int
+	onResult func(index int, result json.RawMessage, err error)
+}
+
+// NewBlockTracer returns a vm.EVMLogger creating a tracer by name for each
+// transaction.
+func NewBlockTracer(name string, cfg json.RawMessage, onResult func(index int, result json.RawMessage, err error)) (*BlockTracer, error) {
+	// fail early on unknown names and bad configurations
+	if _, err := New(name, cfg); err != nil {
+		return nil, err
+	}
+	return &BlockTracer{name: name, cfg: cfg, onResult: onResult}, nil
+}
+
+func (t *BlockTracer) CaptureTxStart(gasLimit uint64) {
+	t.tracer, _ = New(t.name, t.cfg)
+	t.tracer.CaptureTxStart(gasLimit)
+}
+
+func (t *BlockTracer) CaptureTxEnd(restGas uint64) {
+	t.tracer.CaptureTxEnd(restGas)
+	result, err := t.tracer.GetResult()
+	t.onResult(t.index, result, err)
+	t.index++
+}
+
+func (t *BlockTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.tracer.CaptureStart(env, from, to, create, input, gas, value)
+}
+
+func (t *BlockTracer) CaptureEnd(output []byte, gasUsed uint64, d time.Duration, err error) {
+	t.tracer.CaptureEnd(output, gasUsed, d, err)
+}
+
+func (t *BlockTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	t.tracer.CaptureEnter(typ, from, to, input, gas, value)
+}
+
+func (t *BlockTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+	t.tracer.CaptureExit(output, gasUsed, err)
+}
+
+func (t *BlockTracer) CaptureState(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	t.tracer.CaptureState(pc, op, gas, cost, scope, rData, depth, err)
+}
+
+func (t *BlockTracer) CaptureFault(pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+	t.tracer.CaptureFault(pc, op, gas, cost, scope, depth, err)
+}
@@ -0,0 +1,181 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	cmath "github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/oracle"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/tests"
+	"github.com/ethereum/go-ethereum/tracers"
+)
+
+// traceContext is the block a fixture transaction is traced in.
+type traceContext struct {
+	Number     cmath.HexOrDecimal64   `json:"number"`
+	Difficulty *cmath.HexOrDecimal256 `json:"difficulty"`
+	Time       cmath.HexOrDecimal64   `json:"timestamp"`
+	GasLimit   cmath.HexOrDecimal64   `json:"gasLimit"`
+	Miner      common.Address         `json:"miner"`
+	BaseFee    *cmath.HexOrDecimal256 `json:"baseFeePerGas"`
+}
+
+// traceTest is a transaction with the state it runs on and its trace, in the
+// result format of debug_traceTransaction.
+type traceTest struct {
+	Genesis struct {
+		Alloc tests.GenesisAlloc `json:"alloc"`
+	} `json:"genesis"`
+	Context      *traceContext   `json:"context"`
+	Input        string          `json:"input"`
+	TracerConfig json.RawMessage `json:"tracerConfig"`
+	Result       json.RawMessage `json:"result"`
+}
+
+// runTraceTest traces the transaction of the fixture in file and compares the
+// result with the recorded one.
+func runTraceTest(t *testing.T, tracerName string, file string) tracers.Tracer {
+	blob, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	test := new(traceTest)
+	if err := json.Unmarshal(blob, test); err != nil {
+		t.Fatal(err)
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(common.FromHex(test.Input)); err != nil {
+		t.Fatal(err)
+	}
+	config := params.AllEthashProtocolChanges
+	var (
+		number  = new(big.Int).SetUint64(uint64(test.Context.Number))
+		baseFee = (*big.Int)(test.Context.BaseFee)
+		context = vm.BlockContext{
+			CanTransfer: core.CanTransfer,
+			Transfer:    core.Transfer,
+			GetHash:     func(uint64) common.Hash { return common.Hash{} },
+			Coinbase:    test.Context.Miner,
+			BlockNumber: number,
+			Time:        new(big.Int).SetUint64(uint64(test.Context.Time)),
+			Difficulty:  (*big.Int)(test.Context.Difficulty),
+			GasLimit:    uint64(test.Context.GasLimit),
+			BaseFee:     baseFee,
+		}
+	)
+	msg, err := tx.AsMessage(types.MakeSigner(config, number), baseFee)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oracle.UseMemory()
+	root := tests.MakePreState(test.Genesis.Alloc)
+	statedb, err := state.New(root, state.NewDatabase(types.Header{Number: new(big.Int).Sub(number, common.Big1), Root: root}), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tracer, err := tracers.New(tracerName, test.TracerConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	evm := vm.NewEVM(context, core.NewEVMTxContext(msg), statedb, config, vm.Config{Debug: true, Tracer: tracer})
+	if _, err := core.ApplyMessage(evm, msg, new(core.GasPool).AddGas(math.MaxUint64)); err != nil {
+		t.Fatal(err)
+	}
+	res, err := tracer.GetResult()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var have, want interface{}
+	if err := json.Unmarshal(res, &have); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(test.Result, &want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(have, want) {
+		t.Errorf("trace mismatch\nhave: %s\nwant: %s", res, test.Result)
+	}
+	return tracer
+}
+
+func TestPrestateTracer(t *testing.T) {
+	testPrestateTracer(t, "prestate_tracer")
+}
+
+func TestPrestateTracerDiffMode(t *testing.T) {
+	testPrestateTracer(t, "prestate_tracer_with_diff_mode")
+}
+
+// testPrestateTracer runs the fixtures in dir and checks that the witness of
+// the tracer lists everything read, in diff mode as well. That is all of the
+// prestate in the default mode fixture of the same name.
+func testPrestateTracer(t *testing.T, dir string) {
+	files, err := filepath.Glob(filepath.Join("testdata", dir, "*.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) == 0 {
+		t.Fatalf("no fixtures in %s", dir)
+	}
+	for _, file := range files {
+		t.Run(strings.TrimSuffix(filepath.Base(file), ".json"), func(t *testing.T) {
+			tracer := runTraceTest(t, "prestateTracer", file)
+			if have, want := tracer.(*tracers.PrestateTracer).Witness(), prestateWitness(t, filepath.Base(file)); !reflect.DeepEqual(have, want) {
+				t.Errorf("witness mismatch: have %v, want %v", have, want)
+			}
+		})
+	}
+}
+
+// prestateWitness returns the accounts and storage slots of the result of the
+// default mode fixture in name.
+func prestateWitness(t *testing.T, name string) oracle.Witness {
+	blob, err := ioutil.ReadFile(filepath.Join("testdata", "prestate_tracer", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var test struct {
+		Result map[common.Address]struct {
+			Storage map[common.Hash]common.Hash `json:"storage"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(blob, &test); err != nil {
+		t.Fatal(err)
+	}
+	witness := make(oracle.Witness)
+	for addr, acc := range test.Result {
+		witness.Add(addr)
+		for slot := range acc.Storage {
+			witness.Add(addr, slot)
+		}
+	}
+	return witness
+}
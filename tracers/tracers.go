@@ -0,0 +1,89 @@
+// Copyright 2021 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package tracers collects the transaction tracers that can be plugged into the
+// EVM through vm.Config to inspect the execution of a block transition.
+package tracers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// Tracer interface extends vm.EVMLogger and additionally
+// allows collecting the tracing result.
+type Tracer interface {
+	vm.EVMLogger
+	GetResult() (json.RawMessage, error)
+	// Stop terminates execution of the tracer at the first opportune moment.
+	Stop(err error)
+}
+
+// New returns a tracer by name, the empty name selects the struct logger. The
+// names and the json configurations match the ones of debug_traceTransaction.
+func New(name string, cfg json.RawMessage) (Tracer, error) {
+	switch name {
+	case "":
+		var config Config
+		if err := unmarshalConfig(cfg, &config); err != nil {
+			return nil, err
+		}
+		return NewStructLogger(&config), nil
+	case "callTracer":
+		return NewCallTracer(), nil
+	case "4byteTracer":
+		return NewFourByteTracer(), nil
+	case "prestateTracer":
+		var config PrestateConfig
+		if err := unmarshalConfig(cfg, &config); err != nil {
+			return nil, err
+		}
+		return NewPrestateTracer(config), nil
+	}
+	return nil, fmt.Errorf("tracer %s not found", name)
+}
+
+func unmarshalConfig(cfg json.RawMessage, config interface{}) error {
+	if len(cfg) == 0 {
+		return nil
+	}
+	return json.Unmarshal(cfg, config)
+}
+
+func bytesToHex(s []byte) string {
+	return "0x" + common.Bytes2Hex(s)
+}
+
+func bigToHex(n *big.Int) string {
+	if n == nil {
+		return ""
+	}
+	return "0x" + n.Text(16)
+}
+
+func uintToHex(n uint64) string {
+	return "0x" + strconv.FormatUint(n, 16)
+}
+
+func addrToHex(a common.Address) string {
+	return strings.ToLower(a.Hex())
+}
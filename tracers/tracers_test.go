@@ -0,0 +1,193 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package tracers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/oracle"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/tracers"
+)
+
+// callFrame is a frame of the result of the call tracer.
+type callFrame struct {
+	Type    string         `json:"type"`
+	From    common.Address `json:"from"`
+	To      common.Address `json:"to"`
+	Gas     string         `json:"gas"`
+	GasUsed string         `json:"gasUsed"`
+	Input   string         `json:"input"`
+	Output  string         `json:"output"`
+	Error   string         `json:"error"`
+	Calls   []callFrame    `json:"calls"`
+}
+
+var (
+	sender = common.HexToAddress("0x5e4de4")
+	caller = common.HexToAddress("0xca11e4")
+	callee = common.HexToAddress("0xca11ee")
+)
+
+// newTraceState returns a state where caller calls callee with the selector
+// 0xdeadbeef and ignores that callee reverts with the byte 0x2a.
+func newTraceState(t *testing.T) *state.StateDB {
+	oracle.UseMemory()
+	statedb, err := state.New(types.EmptyRootHash, state.NewDatabase(types.Header{Number: new(big.Int), Root: types.EmptyRootHash}), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	statedb.SetBalance(sender, big.NewInt(1e18))
+	// mstore(0, shl(224, 0xdeadbeef)); pop(call(gas(), callee, 0, 0, 4, 0, 0))
+	code := []byte{0x63, 0xde, 0xad, 0xbe, 0xef, 0x60, 0xe0, 0x1b, 0x60, 0x00, 0x52,
+		0x60, 0x00, 0x60, 0x00, 0x60, 0x04, 0x60, 0x00, 0x60, 0x00, 0x73}
+	code = append(code, callee[:]...)
+	code = append(code, 0x5a, 0xf1, 0x50, 0x00)
+	statedb.SetCode(caller, code)
+	// mstore8(0, 0x2a); revert(0, 1)
+	statedb.SetCode(callee, []byte{0x60, 0x2a, 0x60, 0x00, 0x53, 0x60, 0x01, 0x60, 0x00, 0xfd})
+	return statedb
+}
+
+// runTrace applies a message from sender to caller with the selector
+// 0x12345678 with tracer attached.
+func runTrace(t *testing.T, statedb *state.StateDB, tracer vm.EVMLogger, nonce uint64) *core.ExecutionResult {
+	context := vm.BlockContext{
+		CanTransfer: core.CanTransfer,
+		Transfer:    core.Transfer,
+		GetHash:     func(uint64) common.Hash { return common.Hash{} },
+		BlockNumber: big.NewInt(1),
+		Time:        big.NewInt(1),
+		Difficulty:  big.NewInt(1),
+		GasLimit:    10000000,
+		BaseFee:     big.NewInt(1),
+	}
+	msg := types.NewMessage(sender, &caller, nonce, new(big.Int), 100000, big.NewInt(1), big.NewInt(1), big.NewInt(0), []byte{0x12, 0x34, 0x56, 0x78}, nil, false)
+	evm := vm.NewEVM(context, core.NewEVMTxContext(msg), statedb, params.AllEthashProtocolChanges, vm.Config{Debug: true, Tracer: tracer})
+	result, err := core.ApplyMessage(evm, msg, new(core.GasPool).AddGas(math.MaxUint64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestCallTracer(t *testing.T) {
+	tracer := tracers.NewCallTracer()
+	result := runTrace(t, newTraceState(t), tracer, 0)
+	res, err := tracer.GetResult()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var frame callFrame
+	if err := json.Unmarshal(res, &frame); err != nil {
+		t.Fatal(err)
+	}
+	if frame.Type != "CALL" || frame.From != sender || frame.To != caller || frame.Input != "0x12345678" {
+		t.Errorf("top frame mismatch: %s", res)
+	}
+	if used := fmt.Sprintf("0x%x", result.UsedGas); frame.Gas != "0x186a0" || frame.GasUsed != used || frame.Error != "" {
+		t.Errorf("top frame gas: have %s used %s error %q, want 0x186a0 used %s", frame.Gas, frame.GasUsed, frame.Error, used)
+	}
+	if len(frame.Calls) != 1 {
+		t.Fatalf("nested calls: have %d, want 1", len(frame.Calls))
+	}
+	call := frame.Calls[0]
+	if call.Type != "CALL" || call.From != caller || call.To != callee || call.Input != "0xdeadbeef" {
+		t.Errorf("nested frame mismatch: %s", res)
+	}
+	if call.Error != vm.ErrExecutionReverted.Error() || call.Output != "0x2a" {
+		t.Errorf("nested frame result: have error %q output %s, want %q output 0x2a", call.Error, call.Output, vm.ErrExecutionReverted)
+	}
+}
+
+func TestFourByteTracer(t *testing.T) {
+	tracer := tracers.NewFourByteTracer()
+	runTrace(t, newTraceState(t), tracer, 0)
+	res, err := tracer.GetResult()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"0x12345678-0":1,"0xdeadbeef-0":1}`; string(res) != want {
+		t.Errorf("have %s, want %s", res, want)
+	}
+}
+
+func TestStructLogger(t *testing.T) {
+	tracer := tracers.NewStructLogger(nil)
+	result := runTrace(t, newTraceState(t), tracer, 0)
+	res, err := tracer.GetResult()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var trace tracers.ExecutionResult
+	if err := json.Unmarshal(res, &trace); err != nil {
+		t.Fatal(err)
+	}
+	if trace.Gas != result.UsedGas || trace.Failed || trace.ReturnValue != "" {
+		t.Errorf("result mismatch: have gas %d failed %v return %q, want gas %d", trace.Gas, trace.Failed, trace.ReturnValue, result.UsedGas)
+	}
+	// the caller runs 15 steps at depth 1, the callee 6 at depth 2
+	var depths [3]int
+	for _, log := range trace.StructLogs {
+		depths[log.Depth]++
+	}
+	if depths[1] != 15 || depths[2] != 6 {
+		t.Errorf("steps per depth: have %v, want [0 15 6]", depths)
+	}
+	first, last := trace.StructLogs[0], trace.StructLogs[len(trace.StructLogs)-1]
+	if first.Op != "PUSH4" || first.Pc != 0 || first.Depth != 1 {
+		t.Errorf("first step: %+v", first)
+	}
+	if last.Op != "STOP" || last.Depth != 1 {
+		t.Errorf("last step: %+v", last)
+	}
+}
+
+func TestBlockTracer(t *testing.T) {
+	var results []string
+	tracer, err := tracers.NewBlockTracer("4byteTracer", nil, func(index int, result json.RawMessage, err error) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if index != len(results) {
+			t.Fatalf("result index: have %d, want %d", index, len(results))
+		}
+		results = append(results, string(result))
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	statedb := newTraceState(t)
+	runTrace(t, statedb, tracer, 0)
+	runTrace(t, statedb, tracer, 1)
+	want := `{"0x12345678-0":1,"0xdeadbeef-0":1}`
+	if len(results) != 2 || results[0] != want || results[1] != want {
+		t.Errorf("have %v, want two times %s", results, want)
+	}
+	if _, err := tracers.NewBlockTracer("noTracer", nil, nil); err == nil {
+		t.Error("unknown tracer name accepted")
+	}
+}
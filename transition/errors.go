@@ -0,0 +1,66 @@
+package transition
+
+import "errors"
+
+var (
+	// ErrMissingParent is returned when the oracle doesn't know the parent header.
+	ErrMissingParent = errors.New("missing parent header")
+
+	// ErrBadParent is returned when the parent header can't be decoded.
+	ErrBadParent = errors.New("bad parent header")
+
+	// ErrMissingHeader is returned when the oracle doesn't know the header of
+	// the block.
+	ErrMissingHeader = errors.New("missing block header")
+
+	// ErrBadHeader is returned when the header of the block can't be decoded or
+	// disagrees with the inputs.
+	ErrBadHeader = errors.New("bad block header")
+
+	// ErrInvalidHeader is returned when the header of the block breaks the
+	// consensus rules, e.g. its seal or difficulty is wrong.
+	ErrInvalidHeader = errors.New("invalid block header")
+
+	// ErrBadCache is returned when the ethash verification cache of the inputs
+	// is missing from the oracle or has the wrong size.
+	ErrBadCache = errors.New("bad ethash cache")
+
+	// ErrMissingTd is returned when the inputs lack the total difficulty of a
+	// proof-of-work parent on a chain that switches to proof-of-stake.
+	ErrMissingTd = errors.New("missing total difficulty")
+
+	// ErrUnknownChain is returned when the chain ID of the inputs isn't a known
+	// network or differs from the one of the chain config.
+	ErrUnknownChain = errors.New("unknown chain")
+
+	// ErrBadConfig is returned when the chain config is inconsistent, e.g. it
+	// enables a precompiled contract set core/vm doesn't provide.
+	ErrBadConfig = errors.New("bad chain config")
+
+	// ErrBadTransactions is returned when the transaction trie can't be read or
+	// doesn't match the transactions root of the inputs.
+	ErrBadTransactions = errors.New("bad transactions")
+
+	// ErrBadUncles is returned when the uncles can't be read or don't match the
+	// uncle hash of the inputs.
+	ErrBadUncles = errors.New("bad uncles")
+
+	// ErrInvalidUncles is returned when the uncles break the consensus rules.
+	ErrInvalidUncles = errors.New("invalid uncles")
+
+	// ErrBadAncestors is returned when the ancestor hashes of the inputs don't
+	// list the ancestors of the parent block.
+	ErrBadAncestors = errors.New("bad ancestor hashes")
+
+	// ErrInvalidBlock is returned when processing the transactions fails, e.g. a
+	// transaction with a bad nonce or more gas than the block has left.
+	ErrInvalidBlock = errors.New("invalid block")
+
+	// ErrMissingState is returned when a trie node, code or ancestor block the
+	// block reads isn't in the oracle, e.g. because a witness is incomplete.
+	ErrMissingState = errors.New("missing state")
+
+	// ErrRootMismatch is returned by ProcessWitness when the state root or the
+	// receipt root differs from the one in the block.
+	ErrRootMismatch = errors.New("root mismatch")
+)
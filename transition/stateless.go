@@ -0,0 +1,56 @@
+package transition
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/core/stateless"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/oracle"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+)
+
+// ProcessWitness executes block statelessly. The oracle is emptied and fed
+// only with the witness and the block itself, nothing is fetched. A trie node
+// or code missing from the witness fails with ErrMissingState, a state or
+// receipt root differing from the one in the block with ErrRootMismatch.
+func ProcessWitness(ctx context.Context, config *params.ChainConfig, engine consensus.Engine, block *types.Block, w *stateless.Witness, vmconfig vm.Config) (*Result, error) {
+	oracle.UseMemory()
+	if err := putWitness(w); err != nil {
+		return nil, err
+	}
+	result, err := Process(ctx, config, engine, PutBlock(block, nil), vmconfig)
+	if err != nil {
+		return nil, err
+	}
+	if result.Root != block.Root() {
+		return nil, fmt.Errorf("%w: state root %s, want %s", ErrRootMismatch, result.Root, block.Root())
+	}
+	if result.ReceiptHash != block.ReceiptHash() {
+		return nil, fmt.Errorf("%w: receipt root %s, want %s", ErrRootMismatch, result.ReceiptHash, block.ReceiptHash())
+	}
+	return result, nil
+}
+
+// putWitness puts the headers, trie nodes and codes of the witness into the
+// oracle.
+func putWitness(w *stateless.Witness) error {
+	kw := oracle.PreimageKeyValueWriter{}
+	for _, header := range w.Headers {
+		enc, err := rlp.EncodeToBytes(header)
+		if err != nil {
+			return err
+		}
+		kw.Put(crypto.Keccak256(enc), enc)
+	}
+	for _, set := range []map[string]struct{}{w.Uncles, w.Codes, w.State} {
+		for blob := range set {
+			kw.Put(crypto.Keccak256([]byte(blob)), []byte(blob))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,381 @@
+// Package transition verifies the transition of the state of a block's parent
+// to the state of the block, reading everything from the preimage oracle.
+package transition
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/stateless"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/oracle"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+// Inputs are the words of the input preimage:
+//
+//	0: hash of the parent header
+//	1: transactions root of the block
+//	2: coinbase of the block
+//	3: uncle hash of the block
+//	4: gas limit of the block
+//	5: timestamp of the block
+//	6: chain ID, zero if unknown
+//	7: hash of the block header
+//	8: hash of the ethash verification cache of the block, zero if it isn't
+//	   provided and must be generated
+//	9: total difficulty of the parent, zero if unknown
+//	10: mix digest of the block
+//	11: hash of the ancestor hashes preimage, zero if there is none
+//
+// The header commits to everything else the block has, words 1 to 5 and 10
+// repeat some of its fields for the on-chain side and must agree with it. Word
+// 11 isn't checked against the headers, the on-chain side derives it from
+// BLOCKHASH.
+type Inputs [12]common.Hash
+
+// ReadInputs reads the inputs from the oracle. Words missing from a shorter
+// input preimage are zero.
+func ReadInputs() Inputs {
+	var inputs Inputs
+	inputBytes := oracle.Preimage(oracle.InputHash())
+	for i := 0; i < len(inputs) && i*0x20 < len(inputBytes); i++ {
+		inputs[i] = common.BytesToHash(inputBytes[i*0x20 : i*0x20+0x20])
+	}
+	return inputs
+}
+
+// ChainConfig returns the configuration of the chain the inputs belong to.
+// Inputs without a chain ID are taken to be of the main network.
+func (inputs Inputs) ChainConfig() (*params.ChainConfig, error) {
+	chainID := inputs[6].Big()
+	if chainID.Sign() == 0 {
+		return params.MainnetChainConfig, nil
+	}
+	if config := params.ChainConfigByID(chainID); config != nil {
+		return config, nil
+	}
+	return nil, fmt.Errorf("%w: chain ID %v", ErrUnknownChain, chainID)
+}
+
+// Result is the outcome of processing a block.
+type Result struct {
+	Inputs   Inputs
+	Parent   *types.Header
+	Block    *types.Block
+	Chain    *core.BlockChain
+	StateDB  *state.StateDB // The state after the block, uncommitted
+	Receipts types.Receipts
+	GasUsed  uint64
+	Witness  *stateless.Witness // The chain and state the block read, nil if not recorded
+
+	Root        common.Hash // State root after the block
+	ReceiptHash common.Hash // Root of the receipt trie of the block
+}
+
+// Run verifies the transition described by the inputs in the oracle with the
+// consensus engine the chain config selects. The state root and receipt hash
+// of the result are what the oracle expects as output.
+func Run(ctx context.Context, config *params.ChainConfig, vmconfig vm.Config) (*Result, error) {
+	return Process(ctx, config, nil, ReadInputs(), vmconfig)
+}
+
+// RunWithWitness is Run, recording the witness of the block in the result.
+func RunWithWitness(ctx context.Context, config *params.ChainConfig, vmconfig vm.Config) (*Result, error) {
+	return ProcessWithWitness(ctx, config, nil, ReadInputs(), vmconfig)
+}
+
+// Process reads the block described by inputs from the oracle, verifies its
+// header and processes it on top of the state of its parent. The consensus
+// engine is the one the chain config selects, unless engine isn't nil.
+//
+// The context is checked before every stage, the processing of the
+// transactions isn't interrupted.
+func Process(ctx context.Context, config *params.ChainConfig, engine consensus.Engine, inputs Inputs, vmconfig vm.Config) (*Result, error) {
+	return process(ctx, config, engine, inputs, vmconfig, false)
+}
+
+// ProcessWithWitness is Process, recording every header, trie node and code
+// read while processing the block into the witness of the result.
+func ProcessWithWitness(ctx context.Context, config *params.ChainConfig, engine consensus.Engine, inputs Inputs, vmconfig vm.Config) (*Result, error) {
+	return process(ctx, config, engine, inputs, vmconfig, true)
+}
+
+func process(ctx context.Context, config *params.ChainConfig, engine consensus.Engine, inputs Inputs, vmconfig vm.Config, record bool) (*Result, error) {
+	if chainID := inputs[6].Big(); chainID.Sign() > 0 && chainID.Cmp(config.ChainID) != 0 {
+		return nil, fmt.Errorf("%w: chain ID %v, config of chain %v", ErrUnknownChain, chainID, config.ChainID)
+	}
+	if err := config.CheckConfigForkOrder(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadConfig, err)
+	}
+	if err := vm.CheckPrecompileUpgrades(config); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadConfig, err)
+	}
+	// read start block header
+	parentRlp := oracle.Preimage(inputs[0])
+	if parentRlp == nil {
+		return nil, fmt.Errorf("%w: %s", ErrMissingParent, inputs[0])
+	}
+	var parent types.Header
+	if err := rlp.DecodeBytes(parentRlp, &parent); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadParent, err)
+	}
+
+	// read header
+	headerRlp := oracle.Preimage(inputs[7])
+	if headerRlp == nil {
+		return nil, fmt.Errorf("%w: %s", ErrMissingHeader, inputs[7])
+	}
+	var newheader types.Header
+	if err := rlp.DecodeBytes(headerRlp, &newheader); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadHeader, err)
+	}
+	if err := checkHeader(&newheader, inputs); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadHeader, err)
+	}
+
+	var bc *core.BlockChain
+	if engine == nil {
+		bc = core.NewBlockChain(config, &parent)
+	} else {
+		bc = core.NewBlockChainWithEngine(config, &parent, engine)
+	}
+	// a zero total difficulty means the node didn't report it
+	if td := inputs[9].Big(); td.Sign() > 0 {
+		bc.SetTd(td)
+	} else if config.TerminalTotalDifficulty != nil && parent.Difficulty.Sign() > 0 {
+		// only the total difficulty tells if a child of a proof-of-work block
+		// is the first proof-of-stake one
+		return nil, fmt.Errorf("%w: parent %d is a proof-of-work block", ErrMissingTd, parent.Number)
+	}
+	bc.SetAncestorHashes(inputs[11])
+	if inputs[8] != (common.Hash{}) {
+		if pow := ethashEngine(bc.Engine()); pow != nil {
+			if err := pow.LoadCache(newheader.Number.Uint64(), oracle.Preimage(inputs[8])); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrBadCache, err)
+			}
+		}
+	}
+	// the seal, difficulty, base fee and extra-data follow the consensus rules
+	if err := bc.Engine().VerifyHeader(bc, &newheader, true); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidHeader, err)
+	}
+	// The EVM context takes the coinbase from the engine, a signer that can't be
+	// recovered must not turn into the zero address there.
+	if _, err := bc.Engine().Author(&newheader); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidHeader, err)
+	}
+	database := state.NewDatabase(parent)
+	statedb, err := state.New(parent.Root, database, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMissingState, err)
+	}
+	var witness *stateless.Witness
+	if record {
+		witness = stateless.NewWitness(&parent)
+		bc.StartWitness(witness)
+		statedb.StartWitness(witness)
+	}
+	// load the tries of what the transactions change in the background
+	statedb.StartPrefetcher()
+	defer statedb.StopPrefetcher()
+	processor := core.NewStateProcessor(config, bc, bc.Engine())
+
+	// read txs
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	txs, err := readTransactions(parent, newheader.TxHash)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadTransactions, err)
+	}
+	// TODO: OMG the transaction ordering isn't fixed
+
+	var uncles []*types.Header
+	if err := rlp.DecodeBytes(oracle.Preimage(newheader.UncleHash), &uncles); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBadUncles, err)
+	}
+
+	// if this is correct, the trie is working
+	if hash := types.DeriveSha(types.Transactions(txs), trie.NewStackTrie(nil)); hash != newheader.TxHash {
+		return nil, fmt.Errorf("%w: transactions root %s, want %s", ErrBadTransactions, hash, newheader.TxHash)
+	}
+	if hash := types.CalcUncleHash(uncles); hash != newheader.UncleHash {
+		return nil, fmt.Errorf("%w: uncle hash %s, want %s", ErrBadUncles, hash, newheader.UncleHash)
+	}
+	block := types.NewBlockWithHeader(&newheader).WithBody(txs, uncles)
+	// uncles are rewarded in finalize, so they must follow the consensus rules
+	if err := bc.Engine().VerifyUncles(bc, block); err != nil {
+		// an ancestor missing from the oracle isn't a bad uncle
+		if chainErr := bc.Error(); chainErr != nil {
+			return nil, fmt.Errorf("%w: %v", ErrMissingState, chainErr)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidUncles, err)
+	}
+
+	// validateState is more complete, gas used + bloom also
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	receipts, _, usedGas, err := processor.Process(block, statedb, vmconfig)
+	if err != nil {
+		if dberr := statedb.Error(); dberr != nil {
+			return nil, fmt.Errorf("%w: %v", ErrMissingState, dberr)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBlock, err)
+	}
+	root := statedb.IntermediateRoot(config.IsEIP158(newheader.Number))
+	// a node or code missing from the oracle leaves the state wrong
+	if err := statedb.Error(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMissingState, err)
+	}
+	// so does a part of the chain missing, e.g. the uncles of an ancestor
+	if err := bc.Error(); errors.Is(err, core.ErrBadAncestorHashes) {
+		return nil, fmt.Errorf("%w: %v", ErrBadAncestors, err)
+	} else if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMissingState, err)
+	}
+	return &Result{
+		Inputs:      inputs,
+		Parent:      &parent,
+		Block:       block,
+		Chain:       bc,
+		StateDB:     statedb,
+		Receipts:    receipts,
+		GasUsed:     usedGas,
+		Witness:     witness,
+		Root:        root,
+		ReceiptHash: types.DeriveSha(types.Receipts(receipts), trie.NewStackTrie(nil)),
+	}, nil
+}
+
+// checkHeader checks that the header is the one of the block the inputs
+// describe.
+func checkHeader(header *types.Header, inputs Inputs) error {
+	switch {
+	case header.ParentHash != inputs[0]:
+		return fmt.Errorf("parent hash %s, want %s", header.ParentHash, inputs[0])
+	case header.TxHash != inputs[1]:
+		return fmt.Errorf("transactions root %s, want %s", header.TxHash, inputs[1])
+	case header.Coinbase.Hash() != inputs[2]:
+		return fmt.Errorf("coinbase %s, want %s", header.Coinbase, common.BytesToAddress(inputs[2].Bytes()))
+	case header.UncleHash != inputs[3]:
+		return fmt.Errorf("uncle hash %s, want %s", header.UncleHash, inputs[3])
+	case header.GasLimit != inputs[4].Big().Uint64():
+		return fmt.Errorf("gas limit %d, want %d", header.GasLimit, inputs[4].Big())
+	case header.Time != inputs[5].Big().Uint64():
+		return fmt.Errorf("timestamp %d, want %d", header.Time, inputs[5].Big())
+	case header.MixDigest != inputs[10]:
+		return fmt.Errorf("mix digest %s, want %s", header.MixDigest, inputs[10])
+	}
+	return nil
+}
+
+// ethashEngine returns the ethash engine engine runs on, nil if there is none.
+func ethashEngine(engine consensus.Engine) *ethash.Ethash {
+	if b, ok := engine.(*beacon.Beacon); ok {
+		engine = b.InnerEngine()
+	}
+	pow, _ := engine.(*ethash.Ethash)
+	return pow
+}
+
+// StateDiff returns the accounts and storage slots the block changed, with
+// their values before and after. It must be called before the state is
+// committed.
+func (r *Result) StateDiff() (state.StateDiff, error) {
+	parent, err := state.New(r.Parent.Root, state.NewDatabase(*r.Parent), nil)
+	if err != nil {
+		return nil, err
+	}
+	return r.StateDB.Diff(parent), nil
+}
+
+// readTransactions reads the transactions of the trie with root txHash, in
+// order of their index.
+func readTransactions(parent types.Header, txHash common.Hash) ([]*types.Transaction, error) {
+	var txs []*types.Transaction
+
+	triedb := trie.NewDatabase(parent)
+	tt, err := trie.New(txHash, &triedb)
+	if err != nil {
+		return nil, err
+	}
+	tni := tt.NodeIterator([]byte{})
+	for tni.Next(true) {
+		if tni.Leaf() {
+			tx := types.Transaction{}
+			var rlpKey uint64
+			if err := rlp.DecodeBytes(tni.LeafKey(), &rlpKey); err != nil {
+				return nil, fmt.Errorf("bad transaction key: %v", err)
+			}
+			if err := tx.UnmarshalBinary(tni.LeafBlob()); err != nil {
+				return nil, fmt.Errorf("bad transaction %d: %v", rlpKey, err)
+			}
+			// TODO: resize an array in go?
+			for uint64(len(txs)) <= rlpKey {
+				txs = append(txs, nil)
+			}
+			txs[rlpKey] = &tx
+		}
+	}
+	if err := tni.Error(); err != nil {
+		return nil, err
+	}
+	for i, tx := range txs {
+		if tx == nil {
+			return nil, fmt.Errorf("missing transaction %d", i)
+		}
+	}
+	return txs, nil
+}
+
+// PutBlock puts the header, the transactions and the uncles of block into the
+// oracle and returns the inputs describing it. A nil parentTd leaves the total
+// difficulty of the parent unknown.
+func PutBlock(block *types.Block, parentTd *big.Int) Inputs {
+	kw := oracle.PreimageKeyValueWriter{}
+	enc, err := rlp.EncodeToBytes(block.Header())
+	if err != nil {
+		panic(err)
+	}
+	kw.Put(crypto.Keccak256(enc), enc)
+	txs := trie.NewStackTrie(kw)
+	types.DeriveSha(block.Transactions(), txs)
+	if _, err := txs.Commit(); err != nil {
+		panic(err)
+	}
+	uncles, err := rlp.EncodeToBytes(block.Uncles())
+	if err != nil {
+		panic(err)
+	}
+	kw.Put(crypto.Keccak256(uncles), uncles)
+
+	header := block.Header()
+	inputs := Inputs{
+		header.ParentHash,
+		header.TxHash,
+		header.Coinbase.Hash(),
+		header.UncleHash,
+		common.BigToHash(new(big.Int).SetUint64(header.GasLimit)),
+		common.BigToHash(new(big.Int).SetUint64(header.Time)),
+	}
+	inputs[7] = block.Hash()
+	if parentTd != nil {
+		inputs[9] = common.BigToHash(parentTd)
+	}
+	inputs[10] = header.MixDigest
+	return inputs
+}
@@ -0,0 +1,206 @@
+// Copyright 2022 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package transition
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/beacon"
+	"github.com/ethereum/go-ethereum/consensus/ethash"
+	"github.com/ethereum/go-ethereum/consensus/misc"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/stateless"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/oracle"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/trie"
+)
+
+var (
+	testKey, _ = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testSender = crypto.PubkeyToAddress(testKey.PublicKey)
+)
+
+// putTestParent puts block 10 of a proof-of-work chain into an emptied
+// oracle, with the headers of its ancestors and the state setup builds, in
+// which testSender has 1 ether. It's the parent of the blocks of
+// newTestHeader.
+func putTestParent(t *testing.T, setup func(statedb *state.StateDB)) *types.Header {
+	oracle.UseMemory()
+	var parent *types.Header
+	for i := int64(0); i <= 10; i++ {
+		header := &types.Header{
+			Number:     big.NewInt(i),
+			Root:       types.EmptyRootHash,
+			Difficulty: big.NewInt(131072),
+			GasLimit:   8000000,
+			Time:       uint64(i) * 10,
+			BaseFee:    big.NewInt(params.InitialBaseFee),
+			UncleHash:  types.EmptyUncleHash,
+		}
+		if parent != nil {
+			header.ParentHash = parent.Hash()
+		}
+		if i == 10 {
+			statedb, err := state.New(types.EmptyRootHash, state.NewDatabase(*header), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			statedb.AddBalance(testSender, big.NewInt(1e18))
+			setup(statedb)
+			if header.Root, err = statedb.Commit(true); err != nil {
+				t.Fatal(err)
+			}
+		}
+		enc, err := rlp.EncodeToBytes(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		oracle.PreimageKeyValueWriter{}.Put(crypto.Keccak256(enc), enc)
+		parent = header
+	}
+	return parent
+}
+
+// newTestHeader returns the header of an empty child of parent. The fields
+// that result from processing the block are left empty.
+func newTestHeader(config *params.ChainConfig, parent *types.Header) *types.Header {
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		UncleHash:  types.EmptyUncleHash,
+		Coinbase:   common.HexToAddress("0xc014ba5e"),
+		TxHash:     types.EmptyRootHash,
+		Number:     new(big.Int).Add(parent.Number, common.Big1),
+		GasLimit:   parent.GasLimit,
+		Time:       parent.Time + 10,
+		Difficulty: ethash.CalcDifficulty(config, parent.Time+10, parent),
+	}
+	if config.IsLondon(header.Number) {
+		header.BaseFee = misc.CalcBaseFee(config, parent)
+	}
+	return header
+}
+
+// newTestBlock returns a child of parent with transactions from testSender to
+// each of the addresses.
+func newTestBlock(t *testing.T, config *params.ChainConfig, parent *types.Header, to ...common.Address) *types.Block {
+	signer := types.NewEIP155Signer(config.ChainID)
+	var txs types.Transactions
+	for i, addr := range to {
+		tx, err := types.SignTx(types.NewTransaction(uint64(i), addr, new(big.Int), 100000, big.NewInt(2e9), nil), signer, testKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		txs = append(txs, tx)
+	}
+	header := newTestHeader(config, parent)
+	header.TxHash = types.DeriveSha(txs, trie.NewStackTrie(nil))
+	return types.NewBlockWithHeader(header).WithBody(txs, nil)
+}
+
+// TestMissingTd checks that a chain switching to proof-of-stake needs the
+// total difficulty of a proof-of-work parent.
+func TestMissingTd(t *testing.T) {
+	config := *params.AllEthashProtocolChanges
+	config.TerminalTotalDifficulty = big.NewInt(1e18)
+	engine := beacon.New(ethash.NewFaker())
+
+	parent := putTestParent(t, func(statedb *state.StateDB) {})
+	inputs := PutBlock(newTestBlock(t, &config, parent), nil)
+	if _, err := Process(context.Background(), &config, engine, inputs, vm.Config{}); !errors.Is(err, ErrMissingTd) {
+		t.Errorf("have %v, want %v", err, ErrMissingTd)
+	}
+	inputs = PutBlock(newTestBlock(t, &config, parent), big.NewInt(11*131072))
+	if _, err := Process(context.Background(), &config, engine, inputs, vm.Config{}); err != nil {
+		t.Errorf("total difficulty known: %v", err)
+	}
+	// a chain without the switch doesn't need it
+	inputs = PutBlock(newTestBlock(t, params.AllEthashProtocolChanges, parent), nil)
+	if _, err := Process(context.Background(), params.AllEthashProtocolChanges, engine, inputs, vm.Config{}); err != nil {
+		t.Errorf("chain without terminal total difficulty: %v", err)
+	}
+}
+
+// TestWitnessStorageDeletion records the witness of a block that clears a
+// storage slot and destroys an account with storage, and runs the block again
+// on the witness alone. Deleting from a trie resolves the sibling of the
+// removed node, which the witness has to carry.
+func TestWitnessStorageDeletion(t *testing.T) {
+	var (
+		config   = params.AllEthashProtocolChanges
+		engine   = beacon.New(ethash.NewFaker())
+		contract = common.HexToAddress("0xc0de")
+		victim   = common.HexToAddress("0xdead")
+	)
+	parent := putTestParent(t, func(statedb *state.StateDB) {
+		// sstore(1, 0)
+		statedb.SetCode(contract, []byte{0x60, 0x00, 0x60, 0x01, 0x55, 0x00})
+		// selfdestruct(caller)
+		statedb.SetCode(victim, []byte{0x33, 0xff})
+		// large values, so that the leaves are stored by hash
+		for _, addr := range []common.Address{contract, victim} {
+			statedb.SetState(addr, common.HexToHash("0x01"), crypto.Keccak256Hash([]byte{1}))
+			statedb.SetState(addr, common.HexToHash("0x02"), crypto.Keccak256Hash([]byte{2}))
+		}
+		for i := int64(0); i < 32; i++ {
+			statedb.AddBalance(common.BigToAddress(big.NewInt(0x1000+i)), common.Big1)
+		}
+	})
+	block := newTestBlock(t, config, parent, contract, victim)
+	result, err := ProcessWithWitness(context.Background(), config, engine, PutBlock(block, nil), vm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.StateDB.Exist(victim) || result.StateDB.GetState(contract, common.HexToHash("0x01")) != (common.Hash{}) {
+		t.Fatal("storage not deleted")
+	}
+	header := block.Header()
+	header.Root, header.ReceiptHash, header.GasUsed = result.Root, result.ReceiptHash, result.GasUsed
+	header.Bloom = types.CreateBloom(result.Receipts)
+	block = types.NewBlockWithHeader(header).WithBody(block.Transactions(), nil)
+
+	// the witness goes through its encoding, as it would between two machines
+	enc, err := json.Marshal(result.Witness)
+	if err != nil {
+		t.Fatal(err)
+	}
+	witness := new(stateless.Witness)
+	if err := json.Unmarshal(enc, witness); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ProcessWitness(context.Background(), config, engine, block, witness, vm.Config{}); err != nil {
+		t.Fatalf("stateless execution failed: %v", err)
+	}
+	// every node of the witness is needed. A missing sibling of a deleted node
+	// is taken for a full node by the trie, so it shows in the root only.
+	for node := range result.Witness.State {
+		delete(witness.State, node)
+		_, err := ProcessWitness(context.Background(), config, engine, block, witness, vm.Config{})
+		if !errors.Is(err, ErrMissingState) && !errors.Is(err, ErrRootMismatch) {
+			t.Errorf("without node %x: have %v, want %v or %v", crypto.Keccak256([]byte(node)), err, ErrMissingState, ErrRootMismatch)
+		}
+		witness.State[node] = struct{}{}
+	}
+}
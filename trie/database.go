@@ -43,6 +43,17 @@ type Database struct {
 	BlockNumber *big.Int
 	Root        common.Hash
 	lock        sync.RWMutex
+	recorder    Recorder // Told about every resolved node, nil if not recording
+}
+
+// Recorder collects the trie nodes a database resolves, to build a witness.
+type Recorder interface {
+	AddState(node []byte)
+}
+
+// SetRecorder makes the database report every node it resolves to r.
+func (db *Database) SetRecorder(r Recorder) {
+	db.recorder = r
 }
 
 func NewDatabase(header types.Header) Database {
@@ -65,18 +76,19 @@ func (db *Database) Node(hash common.Hash) ([]byte, error) {
 // found in the memory cache.
 func (db *Database) node(hash common.Hash) node {
 	if val := oracle.Preimage(hash); val != nil {
+		if db.recorder != nil {
+			db.recorder.AddState(val)
+		}
 		return mustDecodeNode(hash[:], val)
 	}
 	return nil
 }
 
-// insert inserts a collapsed trie node into the memory database.
-// The blob size must be specified to allow proper size tracking.
-// All nodes inserted by this function will be reference tracked
-// and in theory should only used for **trie nodes** insertion.
+// insert inserts a collapsed trie node into the oracle, the only node store
+// here, so a committed trie can be opened again from its root. The write only
+// reaches the in-memory preimages, it's a no-op on MIPS.
 func (db *Database) insert(hash common.Hash, size int, node node) {
-	// can put things in the oracle here if we care
-	//fmt.Println("insert", hash, size)
+	oracle.PreimageKeyValueWriter{}.Put(hash[:], nodeToBytes(node))
 }
 
 func GenPossibleShortNodePreimage(preimages map[common.Hash][]byte) {
@@ -0,0 +1,625 @@
+// Copyright 2015 The go-ethereum Authors
+// This file is part of the go-ethereum library.
+//
+// The go-ethereum library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The go-ethereum library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the go-ethereum library. If not, see <http://www.gnu.org/licenses/>.
+
+package trie
+
+import (
+	"bytes"
+	"encoding/binary"
+	mrand "math/rand"
+	"sort"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/oracle"
+)
+
+// proofDb is an in-memory key-value store for proof nodes.
+type proofDb map[string][]byte
+
+func (db proofDb) Has(key []byte) (bool, error) {
+	_, ok := db[string(key)]
+	return ok, nil
+}
+
+func (db proofDb) Get(key []byte) ([]byte, error) {
+	return db[string(key)], nil
+}
+
+func (db proofDb) Put(key []byte, value []byte) error {
+	db[string(key)] = common.CopyBytes(value)
+	return nil
+}
+
+func (db proofDb) Delete(key []byte) error {
+	delete(db, string(key))
+	return nil
+}
+
+type kv struct {
+	k, v []byte
+}
+
+type entrySlice []*kv
+
+func (p entrySlice) Len() int           { return len(p) }
+func (p entrySlice) Less(i, j int) bool { return bytes.Compare(p[i].k, p[j].k) < 0 }
+func (p entrySlice) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+
+// randomTrie returns an in-memory trie of n leaves with random 32 byte keys,
+// plus 100 leaves with short keys, and its entries.
+func randomTrie(n int) (*Trie, map[string]*kv) {
+	rand := mrand.New(mrand.NewSource(int64(n)))
+	trie := &Trie{db: new(Database)}
+	vals := make(map[string]*kv)
+	for i := byte(0); i < 100; i++ {
+		value := &kv{common.LeftPadBytes([]byte{i}, 32), []byte{i}}
+		value2 := &kv{common.LeftPadBytes([]byte{i + 10}, 32), []byte{i}}
+		trie.Update(value.k, value.v)
+		trie.Update(value2.k, value2.v)
+		vals[string(value.k)] = value
+		vals[string(value2.k)] = value2
+	}
+	for i := 0; i < n; i++ {
+		k := make([]byte, 32)
+		rand.Read(k)
+		v := make([]byte, 20)
+		binary.BigEndian.PutUint64(v, uint64(i))
+		value := &kv{k, v}
+		trie.Update(k, v)
+		vals[string(k)] = value
+	}
+	return trie, vals
+}
+
+func TestProof(t *testing.T) {
+	trie, vals := randomTrie(500)
+	root := trie.Hash()
+	for _, kv := range vals {
+		proof := make(proofDb)
+		if err := trie.Prove(kv.k, 0, proof); err != nil {
+			t.Fatalf("missing key %x while constructing proof", kv.k)
+		}
+		val, err := VerifyProof(root, kv.k, proof)
+		if err != nil {
+			t.Fatalf("VerifyProof error for key %x: %v\nraw proof: %x", kv.k, err, proof)
+		}
+		if !bytes.Equal(val, kv.v) {
+			t.Fatalf("VerifyProof returned wrong value for key %x: got %x, want %x", kv.k, val, kv.v)
+		}
+	}
+}
+
+func TestOneElementProof(t *testing.T) {
+	trie := &Trie{db: new(Database)}
+	updateString(trie, "k", "v")
+	proof := make(proofDb)
+	trie.Prove([]byte("k"), 0, proof)
+	if len(proof) != 1 {
+		t.Error("proof should have one element")
+	}
+	val, err := VerifyProof(trie.Hash(), []byte("k"), proof)
+	if err != nil {
+		t.Fatalf("VerifyProof error: %v\nproof hashes: %v", err, proof)
+	}
+	if !bytes.Equal(val, []byte("v")) {
+		t.Fatalf("VerifyProof returned wrong value: got %x, want 'k'", val)
+	}
+}
+
+func TestMissingKeyProof(t *testing.T) {
+	trie := &Trie{db: new(Database)}
+	updateString(trie, "k", "v")
+
+	for i, key := range []string{"a", "j", "l", "z"} {
+		proof := make(proofDb)
+		trie.Prove([]byte(key), 0, proof)
+
+		if len(proof) != 1 {
+			t.Errorf("test %d: proof should have one element", i)
+		}
+		val, err := VerifyProof(trie.Hash(), []byte(key), proof)
+		if err != nil {
+			t.Fatalf("test %d: failed to verify proof: %v\nraw proof: %x", i, err, proof)
+		}
+		if val != nil {
+			t.Fatalf("test %d: verified value mismatch: have %x, want nil", i, val)
+		}
+	}
+}
+
+// TestAbsentKeyProof checks the proofs of keys missing from a larger trie,
+// they end at the node that proves the absence.
+func TestAbsentKeyProof(t *testing.T) {
+	trie, vals := randomTrie(500)
+	root := trie.Hash()
+	for i := 0; i < 100; i++ {
+		key := crypto.Keccak256([]byte{byte(i)})
+		if _, ok := vals[string(key)]; ok {
+			continue
+		}
+		proof := make(proofDb)
+		if err := trie.Prove(key, 0, proof); err != nil {
+			t.Fatal(err)
+		}
+		val, err := VerifyProof(root, key, proof)
+		if err != nil {
+			t.Fatalf("key %x: failed to verify absence: %v", key, err)
+		}
+		if val != nil {
+			t.Fatalf("key %x: verified value mismatch: have %x, want nil", key, val)
+		}
+	}
+}
+
+func TestBadProof(t *testing.T) {
+	trie, vals := randomTrie(800)
+	root := trie.Hash()
+	for _, kv := range vals {
+		proof := make(proofDb)
+		if err := trie.Prove(kv.k, 0, proof); err != nil {
+			t.Fatalf("missing key %x while constructing proof", kv.k)
+		}
+		for key, val := range proof {
+			// a mutated node is stored under its own hash
+			mutated := common.CopyBytes(val)
+			mutated[len(mutated)/2] ^= 0x01
+			delete(proof, key)
+			proof.Put(crypto.Keccak256(mutated), mutated)
+			if _, err := VerifyProof(root, kv.k, proof); err == nil {
+				t.Fatalf("expected proof to fail for key %x", kv.k)
+			}
+			delete(proof, string(crypto.Keccak256(mutated)))
+			proof[key] = val
+		}
+		for key := range proof {
+			val := proof[key]
+			delete(proof, key)
+			if _, err := VerifyProof(root, kv.k, proof); err == nil {
+				t.Fatalf("expected proof to fail without node %x for key %x", key, kv.k)
+			}
+			proof[key] = val
+		}
+	}
+}
+
+// TestProofResolved checks the proofs of a trie that is opened from its root
+// in the oracle, every node on the path is resolved while proving.
+func TestProofResolved(t *testing.T) {
+	oracle.UseMemory()
+	trie, vals := randomTrie(500)
+	root, _, err := trie.Commit(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	trie, err = New(root, new(Database))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, kv := range vals {
+		proof := make(proofDb)
+		if err := trie.Prove(kv.k, 0, proof); err != nil {
+			t.Fatalf("key %x: %v", kv.k, err)
+		}
+		val, err := VerifyProof(root, kv.k, proof)
+		if err != nil {
+			t.Fatalf("key %x: %v", kv.k, err)
+		}
+		if !bytes.Equal(val, kv.v) {
+			t.Fatalf("key %x: verified value mismatch: have %x, want %x", kv.k, val, kv.v)
+		}
+	}
+}
+
+// TestSecureProof checks that the proofs of a secure trie are the proofs of
+// the hashed keys.
+func TestSecureProof(t *testing.T) {
+	trie, err := NewSecure(common.Hash{}, new(Database))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys [][]byte
+	for i := 0; i < 100; i++ {
+		key := common.LeftPadBytes([]byte{byte(i)}, 20)
+		trie.Update(key, []byte{byte(i), 0x01})
+		keys = append(keys, key)
+	}
+	root := trie.Hash()
+	for i, key := range keys {
+		proof := make(proofDb)
+		if err := trie.Prove(crypto.Keccak256(key), 0, proof); err != nil {
+			t.Fatal(err)
+		}
+		val, err := VerifyProof(root, crypto.Keccak256(key), proof)
+		if err != nil {
+			t.Fatalf("key %x: %v", key, err)
+		}
+		if !bytes.Equal(val, []byte{byte(i), 0x01}) {
+			t.Fatalf("key %x: verified value mismatch: have %x", key, val)
+		}
+	}
+}
+
+// sortedTrie returns randomTrie with its entries in key order.
+func sortedTrie(n int) (*Trie, entrySlice) {
+	trie, vals := randomTrie(n)
+	var entries entrySlice
+	for _, kv := range vals {
+		entries = append(entries, kv)
+	}
+	sort.Sort(entries)
+	return trie, entries
+}
+
+func rangeOf(entries entrySlice) (keys [][]byte, vals [][]byte) {
+	for _, kv := range entries {
+		keys = append(keys, kv.k)
+		vals = append(vals, kv.v)
+	}
+	return keys, vals
+}
+
+// TestRangeProof tests normal range proof with both edge proofs
+// as the existent proof. The test cases are generated randomly.
+func TestRangeProof(t *testing.T) {
+	trie, entries := sortedTrie(4096)
+	rand := mrand.New(mrand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		start := rand.Intn(len(entries))
+		end := rand.Intn(len(entries)-start) + start + 1
+
+		proof := make(proofDb)
+		if err := trie.Prove(entries[start].k, 0, proof); err != nil {
+			t.Fatalf("Failed to prove the first node %v", err)
+		}
+		if err := trie.Prove(entries[end-1].k, 0, proof); err != nil {
+			t.Fatalf("Failed to prove the last node %v", err)
+		}
+		keys, vals := rangeOf(entries[start:end])
+		_, err := VerifyRangeProof(trie.Hash(), keys[0], keys[len(keys)-1], keys, vals, proof)
+		if err != nil {
+			t.Fatalf("Case %d(%d->%d) expect no error, got %v", i, start, end-1, err)
+		}
+	}
+}
+
+// TestRangeProofWithNonExistentProof tests normal range proof with two
+// non-existent edge proofs. The test cases are generated randomly.
+func TestRangeProofWithNonExistentProof(t *testing.T) {
+	trie, entries := sortedTrie(4096)
+	rand := mrand.New(mrand.NewSource(2))
+	for i := 0; i < 500; i++ {
+		start := rand.Intn(len(entries))
+		end := rand.Intn(len(entries)-start) + start + 1
+		if start == 0 || end == len(entries) {
+			continue
+		}
+		first := decreaseKey(common.CopyBytes(entries[start].k))
+		if bytes.Compare(first, entries[start-1].k) <= 0 {
+			continue
+		}
+		last := increaseKey(common.CopyBytes(entries[end-1].k))
+		if bytes.Compare(last, entries[end].k) >= 0 {
+			continue
+		}
+		proof := make(proofDb)
+		if err := trie.Prove(first, 0, proof); err != nil {
+			t.Fatalf("Failed to prove the first node %v", err)
+		}
+		if err := trie.Prove(last, 0, proof); err != nil {
+			t.Fatalf("Failed to prove the last node %v", err)
+		}
+		keys, vals := rangeOf(entries[start:end])
+		if _, err := VerifyRangeProof(trie.Hash(), first, last, keys, vals, proof); err != nil {
+			t.Fatalf("Case %d(%d->%d) expect no error, got %v", i, start, end-1, err)
+		}
+	}
+}
+
+// TestOneElementRangeProof tests the proof with only one element, the edge
+// proofs are either existent or non-existent.
+func TestOneElementRangeProof(t *testing.T) {
+	trie, entries := sortedTrie(4096)
+	start := 1000
+
+	// One element with existent edge proof, both edge proofs point to the
+	// same key.
+	proof := make(proofDb)
+	if err := trie.Prove(entries[start].k, 0, proof); err != nil {
+		t.Fatalf("Failed to prove the first node %v", err)
+	}
+	_, err := VerifyRangeProof(trie.Hash(), entries[start].k, entries[start].k, [][]byte{entries[start].k}, [][]byte{entries[start].v}, proof)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// One element with left non-existent edge proof
+	first := decreaseKey(common.CopyBytes(entries[start].k))
+	proof = make(proofDb)
+	if err := trie.Prove(first, 0, proof); err != nil {
+		t.Fatalf("Failed to prove the first node %v", err)
+	}
+	if err := trie.Prove(entries[start].k, 0, proof); err != nil {
+		t.Fatalf("Failed to prove the last node %v", err)
+	}
+	_, err = VerifyRangeProof(trie.Hash(), first, entries[start].k, [][]byte{entries[start].k}, [][]byte{entries[start].v}, proof)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// One element with right non-existent edge proof
+	last := increaseKey(common.CopyBytes(entries[start].k))
+	proof = make(proofDb)
+	if err := trie.Prove(entries[start].k, 0, proof); err != nil {
+		t.Fatalf("Failed to prove the first node %v", err)
+	}
+	if err := trie.Prove(last, 0, proof); err != nil {
+		t.Fatalf("Failed to prove the last node %v", err)
+	}
+	_, err = VerifyRangeProof(trie.Hash(), entries[start].k, last, [][]byte{entries[start].k}, [][]byte{entries[start].v}, proof)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Test the mini trie with only a single element.
+	tinyTrie := &Trie{db: new(Database)}
+	entry := &kv{randBytes(32), randBytes(20)}
+	tinyTrie.Update(entry.k, entry.v)
+
+	first = common.HexToHash("0x0000000000000000000000000000000000000000000000000000000000000000").Bytes()
+	last = entry.k
+	proof = make(proofDb)
+	if err := tinyTrie.Prove(first, 0, proof); err != nil {
+		t.Fatalf("Failed to prove the first node %v", err)
+	}
+	if err := tinyTrie.Prove(last, 0, proof); err != nil {
+		t.Fatalf("Failed to prove the last node %v", err)
+	}
+	_, err = VerifyRangeProof(tinyTrie.Hash(), first, last, [][]byte{entry.k}, [][]byte{entry.v}, proof)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+// TestAllElementsProof tests the range proof with all elements.
+// The edge proofs can be nil.
+func TestAllElementsProof(t *testing.T) {
+	trie, entries := sortedTrie(4096)
+	keys, vals := rangeOf(entries)
+
+	_, err := VerifyRangeProof(trie.Hash(), nil, nil, keys, vals, nil)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// With edge proofs, it should still work.
+	proof := make(proofDb)
+	if err := trie.Prove(entries[0].k, 0, proof); err != nil {
+		t.Fatalf("Failed to prove the first node %v", err)
+	}
+	if err := trie.Prove(entries[len(entries)-1].k, 0, proof); err != nil {
+		t.Fatalf("Failed to prove the last node %v", err)
+	}
+	_, err = VerifyRangeProof(trie.Hash(), keys[0], keys[len(keys)-1], keys, vals, proof)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Even with non-existent edge proofs, it should still work.
+	first := common.HexToHash("0x0000000000000000000000000000000000000000000000000000000000000000").Bytes()
+	last := common.HexToHash("0xffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff").Bytes()
+	proof = make(proofDb)
+	if err := trie.Prove(first, 0, proof); err != nil {
+		t.Fatalf("Failed to prove the first node %v", err)
+	}
+	if err := trie.Prove(last, 0, proof); err != nil {
+		t.Fatalf("Failed to prove the last node %v", err)
+	}
+	_, err = VerifyRangeProof(trie.Hash(), first, last, keys, vals, proof)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+}
+
+// TestEmptyRangeProof tests the range proof with "no" element. The first
+// edge proof must be a non-existent proof.
+func TestEmptyRangeProof(t *testing.T) {
+	trie, entries := sortedTrie(4096)
+	var cases = []struct {
+		pos int
+		err bool
+	}{
+		{len(entries) - 1, false},
+		{500, true},
+	}
+	for _, c := range cases {
+		proof := make(proofDb)
+		first := increaseKey(common.CopyBytes(entries[c.pos].k))
+		if err := trie.Prove(first, 0, proof); err != nil {
+			t.Fatalf("Failed to prove the first node %v", err)
+		}
+		_, err := VerifyRangeProof(trie.Hash(), first, nil, nil, nil, proof)
+		if c.err && err == nil {
+			t.Fatalf("Expected error, got nil")
+		}
+		if !c.err && err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+}
+
+// TestBadRangeProof tests a few cases which the proof is wrong.
+// The prover is expected to detect the error.
+func TestBadRangeProof(t *testing.T) {
+	trie, entries := sortedTrie(4096)
+	rand := mrand.New(mrand.NewSource(3))
+	for i := 0; i < 500; i++ {
+		start := rand.Intn(len(entries))
+		end := rand.Intn(len(entries)-start) + start + 1
+		proof := make(proofDb)
+		if err := trie.Prove(entries[start].k, 0, proof); err != nil {
+			t.Fatalf("Failed to prove the first node %v", err)
+		}
+		if err := trie.Prove(entries[end-1].k, 0, proof); err != nil {
+			t.Fatalf("Failed to prove the last node %v", err)
+		}
+		keys, vals := rangeOf(entries[start:end])
+		first, last := keys[0], keys[len(keys)-1]
+		testcase := rand.Intn(5)
+		index := rand.Intn(end - start)
+		switch testcase {
+		case 0:
+			// Modified key
+			keys[index] = randBytes(32) // In theory it can't be same
+		case 1:
+			// Modified val
+			vals[index] = randBytes(20) // In theory it can't be same
+		case 2:
+			// Gapped entry slice
+			if index == 0 || index == end-start-1 {
+				continue
+			}
+			keys = append(keys[:index], keys[index+1:]...)
+			vals = append(vals[:index], vals[index+1:]...)
+		case 3:
+			// Out of order
+			index2 := rand.Intn(end - start)
+			if index2 == index {
+				continue
+			}
+			keys[index], keys[index2] = keys[index2], keys[index]
+			vals[index], vals[index2] = vals[index2], vals[index]
+		case 4:
+			// Set random value to nil, deletion
+			vals[index] = nil
+		}
+		_, err := VerifyRangeProof(trie.Hash(), first, last, keys, vals, proof)
+		if err == nil {
+			t.Fatalf("%d Case %d index %d range: (%d->%d) expect error, got nil", i, testcase, index, start, end-1)
+		}
+	}
+}
+
+// TestHasRightElement checks the flag reporting that the trie has more
+// leaves to the right of the range.
+func TestHasRightElement(t *testing.T) {
+	trie := &Trie{db: new(Database)}
+	var entries entrySlice
+	for i := 0; i < 4096; i++ {
+		value := &kv{common.LeftPadBytes([]byte{byte(i >> 8), byte(i)}, 32), []byte{byte(i + 1)}}
+		trie.Update(value.k, value.v)
+		entries = append(entries, value)
+	}
+	var cases = []struct {
+		start   int
+		end     int
+		hasMore bool
+	}{
+		{0, 1, true},
+		{50, 100, true},
+		{50, len(entries), false},
+		{len(entries) - 1, len(entries), false},
+		{0, len(entries), false},
+	}
+	for _, c := range cases {
+		proof := make(proofDb)
+		if err := trie.Prove(entries[c.start].k, 0, proof); err != nil {
+			t.Fatalf("Failed to prove the first node %v", err)
+		}
+		if err := trie.Prove(entries[c.end-1].k, 0, proof); err != nil {
+			t.Fatalf("Failed to prove the last node %v", err)
+		}
+		keys, vals := rangeOf(entries[c.start:c.end])
+		hasMore, err := VerifyRangeProof(trie.Hash(), keys[0], keys[len(keys)-1], keys, vals, proof)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if hasMore != c.hasMore {
+			t.Fatalf("Wrong hasMore indicator, want %t, got %t", c.hasMore, hasMore)
+		}
+	}
+}
+
+// TestProveRange walks the whole trie in chunks of the range proof generator
+// and verifies every chunk.
+func TestProveRange(t *testing.T) {
+	trie, entries := sortedTrie(4096)
+	root := trie.Hash()
+
+	var (
+		origin []byte
+		all    [][]byte
+	)
+	for {
+		proof := make(proofDb)
+		keys, vals, err := trie.ProveRange(origin, 500, proof)
+		if err != nil {
+			t.Fatal(err)
+		}
+		first := origin
+		if first == nil {
+			first = keys[0]
+		}
+		hasMore, err := VerifyRangeProof(root, first, keys[len(keys)-1], keys, vals, proof)
+		if err != nil {
+			t.Fatalf("chunk at %x: %v", origin, err)
+		}
+		all = append(all, keys...)
+		if !hasMore {
+			break
+		}
+		origin = increaseKey(common.CopyBytes(keys[len(keys)-1]))
+	}
+	if len(all) != len(entries) {
+		t.Fatalf("range leaves: have %d, want %d", len(all), len(entries))
+	}
+	for i, key := range all {
+		if !bytes.Equal(key, entries[i].k) {
+			t.Fatalf("leaf %d: have %x, want %x", i, key, entries[i].k)
+		}
+	}
+}
+
+func randBytes(n int) []byte {
+	r := make([]byte, n)
+	mrand.Read(r)
+	return r
+}
+
+func increaseKey(key []byte) []byte {
+	for i := len(key) - 1; i >= 0; i-- {
+		key[i]++
+		if key[i] != 0x0 {
+			break
+		}
+	}
+	return key
+}
+
+func decreaseKey(key []byte) []byte {
+	for i := len(key) - 1; i >= 0; i-- {
+		key[i]--
+		if key[i] != 0xff {
+			break
+		}
+	}
+	return key
+}
+
+func updateString(trie *Trie, k, v string) {
+	trie.Update([]byte(k), []byte(v))
+}